@@ -3,11 +3,14 @@ package main
 import (
 	"lady/config"
 	"lady/internal/gpt"
+	"lady/internal/media"
 	"lady/internal/repository"
 	"lady/internal/tg"
 	"lady/internal/usecase"
 	"log"
 	"os"
+	"path/filepath"
+	_ "time/tzdata" // часовые пояса редакторов не должны зависеть от tzdata в системе
 )
 
 func main() {
@@ -16,11 +19,19 @@ func main() {
 		log.Fatal(err)
 	}
 
-	repo := repository.NewTopicRepository(cfg.DBPath)
-	uc := usecase.NewTopicUsecase(repo)
+	db, err := repository.Open(cfg.DBPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	gptClient := gpt.NewGroqClient()
-	tuc := usecase.NewGenerateUsecase(gptClient)
 	f, err := os.OpenFile("bot.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Fatal(err)
@@ -29,7 +40,60 @@ func main() {
 
 	log.SetOutput(f)
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	bot := tg.NewBot(cfg.BotToken, uc, tuc, cfg.OpenAIAPIKey)
+
+	// Бот не стартует на устаревшей схеме: недостающие миграции применяются сразу.
+	if _, err := repository.Migrate(db); err != nil {
+		log.Fatalf("Ошибка миграции базы: %v", err)
+	}
+
+	repo := repository.NewTopicRepository(db)
+	uc := usecase.NewTopicUsecase(repo)
+	userRepo := repository.NewUserRepository(db)
+	cuc := usecase.NewChannelUsecase(repository.NewChannelRepository(db), userRepo)
+	uuc := usecase.NewUserUsecase(userRepo)
+	puc := usecase.NewPersonaUsecase(repository.NewPersonaRepository(db))
+
+	cache := repository.NewCacheRepository(db)
+	textGen, err := gpt.NewTextGenerator(cfg.Text, cache, cfg.Cache.TextTTL)
+	if err != nil {
+		log.Fatalf("Ошибка настройки генерации текста: %v", err)
+	}
+	imageGen, err := gpt.NewImageGenerator(cfg.Image, cache, cfg.Cache.ImageTTL)
+	if err != nil {
+		log.Fatalf("Ошибка настройки генерации картинок: %v", err)
+	}
+	usc := usecase.NewUsageUsecase(repository.NewUsageRepository(db), cfg.Usage)
+	tuc := usecase.NewGenerateUsecase(textGen, imageGen, usc)
+
+	mediaDir := cfg.MediaDir
+	if mediaDir == "" {
+		mediaDir = filepath.Join(filepath.Dir(cfg.DBPath), "media")
+	}
+	store, err := media.NewStore(mediaDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	muc := usecase.NewMediaUsecase(repository.NewMediaRepository(db), store)
+
+	rules, err := usecase.LoadModerationRules(cfg.Moderation.RulesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	action, err := usecase.ParseModerationAction(cfg.Moderation.Action)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var moderator gpt.Moderator
+	if cfg.Moderation.Provider.Provider != "" {
+		if moderator, err = gpt.NewModerator(cfg.Moderation.Provider); err != nil {
+			log.Fatalf("Ошибка настройки модерации: %v", err)
+		}
+	}
+	moc := usecase.NewModerationUsecase(rules, moderator, action)
+
+	suc := usecase.NewSeriesUsecase(repository.NewSeriesRepository(db), userRepo)
+
+	bot := tg.NewBot(cfg.BotToken, uc, tuc, cuc, uuc, puc, muc, usc, moc, suc)
 	bot.Start()
 
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"lady/internal/repository"
+)
+
+// runMigrate выполняет подкоманду migrate:
+//
+//	bot migrate         — применить все новые миграции
+//	bot migrate status  — показать текущую версию схемы и ожидающие миграции
+func runMigrate(db *sql.DB, args []string) error {
+	if len(args) == 0 || args[0] == "up" {
+		applied, err := repository.Migrate(db)
+		for _, m := range applied {
+			fmt.Printf("применена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("схема актуальна")
+		}
+		return nil
+	}
+
+	if args[0] != "status" {
+		return fmt.Errorf("неизвестная подкоманда migrate %q: используйте up или status", args[0])
+	}
+
+	current, err := repository.SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	migrations, err := repository.Migrations()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("текущая версия схемы: %d\n", current)
+	for _, m := range migrations {
+		state := "ожидает"
+		if m.Version <= current {
+			state = "применена"
+		}
+		fmt.Printf("%04d_%s\t%s\n", m.Version, m.Name, state)
+	}
+	return nil
+}
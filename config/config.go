@@ -3,14 +3,63 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// ProviderConfig — настройки OpenAI-совместимого провайдера генерации.
+type ProviderConfig struct {
+	Provider  string // openai, groq или local
+	BaseURL   string // пусто — адрес провайдера по умолчанию
+	Model     string // пусто — модель провайдера по умолчанию
+	APIKey    string
+	ImageSize string // только для картинок
+	// JSONMode — как просить у модели JSON: schema, object или text;
+	// пусто — как умеет модель провайдера по умолчанию
+	JSONMode string
+}
+
+// UsageConfig — квоты на генерацию, цены и бюджет. Нулевая квота — без ограничения.
+type UsageConfig struct {
+	DailyTokens   int
+	DailyImages   int
+	MonthlyTokens int
+	MonthlyImages int
+	// Цены в долларах: за 1000 токенов запроса и ответа и за одну картинку
+	PromptPrice     float64
+	CompletionPrice float64
+	ImagePrice      float64
+	MonthlyBudget   float64 // 0 — бюджет не задан
+	AlertPercent    float64 // при какой доле бюджета предупредить администратора
+	AdminChatID     int64   // 0 — предупреждать некого
+}
+
+// CacheConfig — сколько хранить ответы провайдеров. Нулевой срок отключает кэш.
+type CacheConfig struct {
+	TextTTL  time.Duration
+	ImageTTL time.Duration // по ссылке из кэша картинка берется из хранилища, а не скачивается
+}
+
+// ModerationConfig — проверка постов перед публикацией. Сервис модерации
+// включается, если задан MODERATION_PROVIDER.
+type ModerationConfig struct {
+	Provider  ProviderConfig
+	RulesFile string // стоп-слова и регулярные выражения; пусто — без локальных правил
+	Action    string // block или flag для постов, отмеченных сервисом
+}
+
 type Config struct {
-	BotToken     string
-	DBPath       string
-	OpenAIAPIKey string
+	BotToken string
+	DBPath   string
+	MediaDir string // каталог для картинок; пусто — media рядом с базой
+	Text     ProviderConfig
+	Image    ProviderConfig
+	Usage    UsageConfig
+	Cache    CacheConfig
+	// Moderation — проверка перед публикацией
+	Moderation ModerationConfig
 }
 
 func LoadConfig() (*Config, error) {
@@ -19,8 +68,85 @@ func LoadConfig() (*Config, error) {
 		log.Fatal("Ошибка загрузки .env  файла")
 	}
 	return &Config{
-		BotToken:     os.Getenv("BOT_TOKEN"),
-		DBPath:       os.Getenv("DB_PATH"),
-		OpenAIAPIKey: os.Getenv("GROQ_API_KEY"),
+		BotToken: os.Getenv("BOT_TOKEN"),
+		DBPath:   os.Getenv("DB_PATH"),
+		MediaDir: os.Getenv("MEDIA_DIR"),
+		Text:     loadProvider("TEXT"),
+		Image:    loadProvider("IMAGE"),
+		Usage: UsageConfig{
+			DailyTokens:     envInt("QUOTA_DAILY_TOKENS"),
+			DailyImages:     envInt("QUOTA_DAILY_IMAGES"),
+			MonthlyTokens:   envInt("QUOTA_MONTHLY_TOKENS"),
+			MonthlyImages:   envInt("QUOTA_MONTHLY_IMAGES"),
+			PromptPrice:     envFloat("PRICE_PROMPT_1K", 0.03),
+			CompletionPrice: envFloat("PRICE_COMPLETION_1K", 0.06),
+			ImagePrice:      envFloat("PRICE_IMAGE", 0.018),
+			MonthlyBudget:   envFloat("MONTHLY_BUDGET", 0),
+			AlertPercent:    envFloat("BUDGET_ALERT_PERCENT", 80),
+			AdminChatID:     int64(envInt("ADMIN_CHAT_ID")),
+		},
+		Cache: CacheConfig{
+			TextTTL:  envDuration("CACHE_TTL", 24*time.Hour),
+			ImageTTL: envDuration("CACHE_IMAGE_TTL", 50*time.Minute),
+		},
+		Moderation: ModerationConfig{
+			Provider:  loadProvider("MODERATION"),
+			RulesFile: os.Getenv("MODERATION_RULES"),
+			Action:    os.Getenv("MODERATION_ACTION"),
+		},
 	}, nil
 }
+
+// envInt читает целое число из переменной окружения; пустое или неверное значение дает 0.
+func envInt(name string) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil && os.Getenv(name) != "" {
+		log.Printf("Переменная %s должна быть целым числом", name)
+	}
+	return v
+}
+
+// envFloat читает дробное число из переменной окружения или возвращает def.
+func envFloat(name string, def float64) float64 {
+	s := os.Getenv(name)
+	if s == "" {
+		return def
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		log.Printf("Переменная %s должна быть числом, используется %v", name, def)
+		return def
+	}
+	return v
+}
+
+// envDuration читает длительность вида 30m или 24h из переменной окружения или возвращает def.
+func envDuration(name string, def time.Duration) time.Duration {
+	s := os.Getenv(name)
+	if s == "" {
+		return def
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		log.Printf("Переменная %s должна быть длительностью вида 30m или 24h, используется %v", name, def)
+		return def
+	}
+	return v
+}
+
+// loadProvider читает переменные PREFIX_PROVIDER, PREFIX_BASE_URL, PREFIX_MODEL,
+// PREFIX_API_KEY, PREFIX_SIZE и PREFIX_JSON_MODE. Ключ по умолчанию берется из GROQ_API_KEY.
+func loadProvider(prefix string) ProviderConfig {
+	apiKey := os.Getenv(prefix + "_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("GROQ_API_KEY")
+	}
+	return ProviderConfig{
+		Provider:  os.Getenv(prefix + "_PROVIDER"),
+		BaseURL:   os.Getenv(prefix + "_BASE_URL"),
+		Model:     os.Getenv(prefix + "_MODEL"),
+		APIKey:    apiKey,
+		ImageSize: os.Getenv(prefix + "_SIZE"),
+		JSONMode:  os.Getenv(prefix + "_JSON_MODE"),
+	}
+}
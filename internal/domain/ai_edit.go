@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// AIEditStatus — решение редактора по AI-правке.
+type AIEditStatus string
+
+const (
+	AIEditProposed AIEditStatus = "proposed" // правка показана и ждет решения
+	AIEditAccepted AIEditStatus = "accepted" // текст поста заменен правкой
+	AIEditReverted AIEditStatus = "reverted" // у поста остался или вернулся прежний текст
+)
+
+// AIEdit — правка текста поста моделью по инструкции редактора.
+type AIEdit struct {
+	ID          int64
+	PostID      int64
+	Instruction string // например, «сделай короче»
+	Original    string // текст поста до правки
+	Revised     string // текст, предложенный моделью
+	Status      AIEditStatus
+	CreatedAt   time.Time
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Channel — канал, в который бот публикует посты.
+type Channel struct {
+	ID        int64 // ID чата Telegram, например -1001234567890
+	Title     string
+	AddedBy   int64 // редактор, привязавший канал; 0 — канал перенесен миграцией
+	CreatedAt time.Time
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// Media — файл картинки, сохраненный на диске бота.
+type Media struct {
+	ID        int64
+	Path      string // путь к файлу в хранилище
+	SourceURL string // откуда файл скачан; пусто для загруженных редактором
+	MimeType  string
+	Size      int64
+	FileID    string // file_id Telegram после первой отправки; пусто — файл еще не загружался
+	CreatedAt time.Time
+}
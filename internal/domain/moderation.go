@@ -0,0 +1,32 @@
+package domain
+
+import "strings"
+
+// ModerationAction — что делать с постом, на котором сработало правило.
+type ModerationAction string
+
+const (
+	ModerationAllow ModerationAction = ""      // нарушений нет
+	ModerationFlag  ModerationAction = "flag"  // публикация только после подтверждения редактора
+	ModerationBlock ModerationAction = "block" // публиковать нельзя, пока пост не исправят
+)
+
+// ModerationVerdict — итог проверки поста: самое строгое из сработавших
+// действий и описания правил, которые сработали.
+type ModerationVerdict struct {
+	Action  ModerationAction
+	Reasons []string
+}
+
+// Add учитывает сработавшее правило.
+func (v *ModerationVerdict) Add(action ModerationAction, reason string) {
+	if action == ModerationBlock || v.Action == ModerationAllow {
+		v.Action = action
+	}
+	v.Reasons = append(v.Reasons, reason)
+}
+
+// Summary перечисляет сработавшие правила в одну строку.
+func (v ModerationVerdict) Summary() string {
+	return strings.Join(v.Reasons, "; ")
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PromptVariables — переменные, которые можно использовать в шаблонах персоны.
+var PromptVariables = []string{"{topic}", "{length}", "{tone}", "{date}"}
+
+// Persona — голос канала: системная инструкция и шаблон запроса к модели.
+type Persona struct {
+	ID           int64
+	Name         string
+	SystemPrompt string
+	Template     string
+	Tone         string
+	Length       int    // желаемая длина текста в символах
+	ImageStyle   string // общий стиль картинок канала, по-английски
+	ImageCount   int    // сколько картинок рисовать к посту, от 0 до MaxPostImages
+	Structured   bool   // модель отвечает JSON-объектом с частями поста, а не свободным текстом
+	CreatedBy    int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Render подставляет тему, длину, тон и дату в шаблон и системную инструкцию.
+func (p Persona) Render(topic string, date time.Time) (system, prompt string) {
+	r := strings.NewReplacer(
+		"{topic}", topic,
+		"{length}", strconv.Itoa(p.Length),
+		"{tone}", p.Tone,
+		"{date}", date.Format("02.01.2006"),
+	)
+	return r.Replace(p.SystemPrompt), r.Replace(p.Template)
+}
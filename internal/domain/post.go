@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// PostStatus — этап жизненного цикла поста.
+type PostStatus string
+
+const (
+	PostDraft      PostStatus = "draft"      // сгенерирован, ждет решения редактора
+	PostScheduled  PostStatus = "scheduled"  // стоит в очереди на публикацию
+	PostPublishing PostStatus = "publishing" // прямо сейчас отправляется в канал
+	PostPublished  PostStatus = "published"  // опубликован
+	PostFailed     PostStatus = "failed"     // публикация не удалась
+	PostCancelled  PostStatus = "cancelled"  // отменен редактором
+)
+
+// postTransitions перечисляет допустимые переходы между статусами.
+// Пост в publishing уже отправляется в канал, и перепланировать его нельзя:
+// повтор после сбоя ставит RecordPublishFailure, минуя эту таблицу.
+var postTransitions = map[PostStatus][]PostStatus{
+	PostDraft:      {PostScheduled, PostPublishing, PostCancelled},
+	PostScheduled:  {PostScheduled, PostPublishing, PostCancelled},
+	PostPublishing: {PostPublished, PostFailed},
+	PostFailed:     {PostScheduled, PostPublishing, PostCancelled},
+}
+
+// Post — пост канала от генерации до публикации.
+type Post struct {
+	ID          int64
+	AuthorID    int64 // чат редактора, создавшего пост
+	ChannelID   int64 // канал публикации; 0 — канал по умолчанию
+	TopicID     int64 // тема, из которой сгенерирован пост; 0 — без темы
+	Status      PostStatus
+	Text        string
+	VariantID   int64       // выбранный вариант текста
+	SeriesID    int64       // серия, которую продолжает пост; 0 — пост вне серий
+	Images      []PostImage // картинки в порядке показа, не больше MaxPostImages
+	PublishAt   time.Time   // нулевое значение — пост не запланирован
+	PublishedAt time.Time
+	// Attempts — число неудачных попыток публикации, LastError — причина последней.
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time // время следующего повтора; нулевое — без задержки
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// MaxPostImages — сколько картинок помещается в одну медиа-группу Telegram.
+const MaxPostImages = 10
+
+// PostImage — картинка поста.
+type PostImage struct {
+	URL     string // исходный адрес; у сгенерированных картинок он быстро истекает
+	Prompt  string // описание, по которому картинка сгенерирована
+	MediaID int64  // файл в хранилище; 0 — картинка не скачана
+	Path    string // путь к файлу в хранилище
+	FileID  string // file_id Telegram, если файл уже загружался
+}
+
+// CanTransition сообщает, можно ли перевести пост в статус to.
+func (p Post) CanTransition(to PostStatus) bool {
+	for _, s := range postTransitions[p.Status] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Editable сообщает, можно ли еще менять пост: он не ушел в канал и не отменен.
+func (p Post) Editable() bool {
+	return p.Status == PostDraft || p.Status == PostScheduled || p.Status == PostFailed
+}
+
+// Scheduled сообщает, стоит ли пост в очереди публикации.
+func (p Post) Scheduled() bool {
+	return p.Status == PostScheduled
+}
+
+// ErrInvalidTransition возвращается при попытке недопустимой смены статуса.
+type ErrInvalidTransition struct {
+	PostID   int64
+	From, To PostStatus
+}
+
+func (e ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("пост %d нельзя перевести из статуса %s в %s", e.PostID, e.From, e.To)
+}
+
+// Title возвращает название статуса для показа редактору.
+func (s PostStatus) Title() string {
+	switch s {
+	case PostDraft:
+		return "черновик"
+	case PostScheduled:
+		return "запланирован"
+	case PostPublishing:
+		return "публикуется"
+	case PostPublished:
+		return "опубликован"
+	case PostFailed:
+		return "ошибка публикации"
+	case PostCancelled:
+		return "отменен"
+	}
+	return string(s)
+}
+
+// PostVariant — один из сгенерированных вариантов текста поста.
+type PostVariant struct {
+	ID     int64
+	PostID int64
+	Text   string
+	// Персона в формате json вместе с текстом предлагает описания картинок и
+	// время публикации; у остальных вариантов они пустые
+	ImagePrompts []string
+	PublishAt    time.Time
+	CreatedAt    time.Time
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Series — цикл постов, каждый из которых продолжает историю предыдущих.
+type Series struct {
+	ID        int64
+	Title     string
+	CreatedBy int64
+	CreatedAt time.Time
+	Posts     int // сколько постов серии опубликовано; заполняется в списке серий
+}
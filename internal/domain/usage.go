@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// UsageKind — за что списан расход.
+type UsageKind string
+
+const (
+	UsageText  UsageKind = "text"  // запрос к текстовой модели
+	UsageImage UsageKind = "image" // генерация картинки
+)
+
+// Usage — расход на один запрос к провайдеру генерации.
+type Usage struct {
+	UserID           int64
+	ChannelID        int64 // канал, для которого генерировали; 0 — канал не выбран
+	Kind             UsageKind
+	PromptTokens     int
+	CompletionTokens int
+	Images           int
+	Cost             float64 // стоимость в долларах по ценам из настроек
+	CreatedAt        time.Time
+}
+
+// UsageTotals — суммарный расход за период.
+type UsageTotals struct {
+	Tokens   int
+	Images   int
+	Cost     float64
+	Requests int
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// DefaultTimeZone — часовой пояс редакторов, которые не выбрали свой.
+const DefaultTimeZone = "Asia/Novosibirsk"
+
+// User — настройки редактора бота.
+type User struct {
+	ID               int64
+	DefaultChannelID int64  // 0 — канал по умолчанию не выбран
+	TimeZone         string // имя из базы IANA; пустое — DefaultTimeZone
+	SeriesID         int64  // серия, в которой редактор сейчас пишет; 0 — вне серий
+}
+
+// Location возвращает часовой пояс редактора. Если сохраненный пояс
+// не загружается, используется DefaultTimeZone, а затем UTC.
+func (u User) Location() *time.Location {
+	name := u.TimeZone
+	if name == "" {
+		name = DefaultTimeZone
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	if loc, err := time.LoadLocation(DefaultTimeZone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
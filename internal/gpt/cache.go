@@ -0,0 +1,161 @@
+package gpt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Cache хранит ответы провайдеров по ключу до истечения срока.
+type Cache interface {
+	// Get возвращает сохраненный ответ; ok = false, если его нет или срок истек.
+	Get(key string) (value string, ok bool, err error)
+	Put(key, value string, expiresAt time.Time) error
+}
+
+// cacheKey адресует ответ по содержимому запроса: провайдеру, модели и всем
+// параметрам генерации.
+func cacheKey(scope, kind string, params interface{}) string {
+	data, _ := json.Marshal(struct {
+		Scope  string
+		Kind   string
+		Params interface{}
+	}{scope, kind, params})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// responseCache — общая часть кэширующих оберток.
+type responseCache struct {
+	cache Cache
+	scope string // провайдер, адрес и модель
+	ttl   time.Duration
+}
+
+func (c responseCache) get(key string) (string, bool) {
+	value, ok, err := c.cache.Get(key)
+	if err != nil {
+		log.Printf("Ошибка чтения кэша ответов: %v", err)
+		return "", false
+	}
+	return value, ok
+}
+
+func (c responseCache) put(key, value string) {
+	if err := c.cache.Put(key, value, time.Now().Add(c.ttl)); err != nil {
+		log.Printf("Ошибка записи в кэш ответов: %v", err)
+	}
+}
+
+// textParams — параметры текстового запроса, от которых зависит ответ.
+type textParams struct {
+	System      string
+	Prompt      string
+	MaxTokens   int
+	Temperature float64
+	Schema      *JSONSchema // схема целиком: правка схемы под тем же именем меняет ключ
+}
+
+// cachedText отдает повторные текстовые запросы из кэша.
+// Реализует TextGenerator и TextStreamer.
+type cachedText struct {
+	responseCache
+	next TextGenerator
+}
+
+func (c *cachedText) key(req TextRequest) string {
+	params := textParams{System: req.System, Prompt: req.Prompt, MaxTokens: req.MaxTokens, Temperature: req.Temperature, Schema: req.Schema}
+	return cacheKey(c.scope, "text", params)
+}
+
+// accepted сообщает, можно ли хранить и отдавать ответ из кэша.
+func accepted(req TextRequest, text string) bool {
+	return req.Accept == nil || req.Accept(text) == nil
+}
+
+// lookup возвращает ответ из кэша и отмечает в расходе, что токены не тратились.
+func (c *cachedText) lookup(req TextRequest, key string) (string, bool) {
+	if req.NoCache {
+		return "", false
+	}
+	text, ok := c.get(key)
+	if !ok || !accepted(req, text) {
+		return "", false
+	}
+	if req.Usage != nil {
+		*req.Usage = TokenUsage{Cached: true}
+	}
+	return text, true
+}
+
+// store запоминает ответ, если его принял вызывающий.
+func (c *cachedText) store(req TextRequest, key, text string) {
+	if accepted(req, text) {
+		c.put(key, text)
+	}
+}
+
+// GenerateText возвращает ответ из кэша или запрашивает модель и запоминает
+// ответ, если его пропускает req.Accept.
+func (c *cachedText) GenerateText(req TextRequest) (string, error) {
+	key := c.key(req)
+	if text, ok := c.lookup(req, key); ok {
+		return text, nil
+	}
+	text, err := c.next.GenerateText(req)
+	if err != nil {
+		return "", err
+	}
+	c.store(req, key, text)
+	return text, nil
+}
+
+// StreamText отдает ответ из кэша одним фрагментом, а иначе передает фрагменты
+// модели и запоминает весь ответ.
+func (c *cachedText) StreamText(req TextRequest, onDelta func(delta string)) (string, error) {
+	key := c.key(req)
+	if text, ok := c.lookup(req, key); ok {
+		onDelta(text)
+		return text, nil
+	}
+
+	var text string
+	var err error
+	if streamer, ok := c.next.(TextStreamer); ok {
+		text, err = streamer.StreamText(req, onDelta)
+	} else if text, err = c.next.GenerateText(req); err == nil {
+		onDelta(text)
+	}
+	if err != nil {
+		return "", err
+	}
+	c.store(req, key, text)
+	return text, nil
+}
+
+// cachedImages отдает повторные запросы картинок из кэша.
+type cachedImages struct {
+	responseCache
+	next ImageGenerator
+}
+
+// GenerateImage возвращает URL из кэша или рисует картинку и запоминает URL.
+func (c *cachedImages) GenerateImage(req ImageRequest) (string, error) {
+	key := cacheKey(c.scope, "image", req.Prompt)
+	if !req.NoCache {
+		if url, ok := c.get(key); ok {
+			if req.Cached != nil {
+				*req.Cached = true
+			}
+			return url, nil
+		}
+	}
+	url, err := c.next.GenerateImage(req)
+	if err != nil {
+		return "", err
+	}
+	c.put(key, url)
+	return url, nil
+}
@@ -0,0 +1,70 @@
+package gpt
+
+// TextRequest — запрос на генерацию текста.
+type TextRequest struct {
+	System      string // системная инструкция, может быть пустой
+	Prompt      string
+	MaxTokens   int
+	Temperature float64
+	Usage       *TokenUsage // если задан, клиент запишет сюда расход токенов из ответа API
+	NoCache     bool        // не брать ответ из кэша; свежий ответ все равно попадет в кэш
+	// Schema — схема JSON-ответа. Насколько строго модель ей следует, зависит от
+	// Client.JSONMode, поэтому ответ нужно проверять
+	Schema *JSONSchema
+	// Accept проверяет ответ: в кэш попадают и из кэша отдаются только ответы,
+	// которые она пропускает; nil — любые
+	Accept func(text string) error
+}
+
+// JSONSchema — JSON Schema ответа модели (response_format json_schema).
+type JSONSchema struct {
+	Name   string
+	Schema map[string]interface{}
+}
+
+// TokenUsage — расход токенов на один запрос.
+type TokenUsage struct {
+	PromptTokens     int  `json:"prompt_tokens"`
+	CompletionTokens int  `json:"completion_tokens"`
+	Cached           bool `json:"-"` // ответ взят из кэша, токены не расходовались
+}
+
+// TextGenerator генерирует текст по запросу.
+type TextGenerator interface {
+	GenerateText(req TextRequest) (string, error)
+}
+
+// TextStreamer генерирует текст по частям, пока модель отвечает. onDelta
+// получает каждый новый фрагмент; возвращается весь текст.
+type TextStreamer interface {
+	StreamText(req TextRequest, onDelta func(delta string)) (string, error)
+}
+
+// ImageRequest — запрос на генерацию картинки.
+type ImageRequest struct {
+	Prompt  string
+	NoCache bool  // не брать ответ из кэша; свежий ответ все равно попадет в кэш
+	Cached  *bool // если задан, сюда запишется, взят ли ответ из кэша
+}
+
+// ImageGenerator генерирует картинку по описанию и возвращает ее URL.
+type ImageGenerator interface {
+	GenerateImage(req ImageRequest) (string, error)
+}
+
+// ModerationRequest — текст и картинки (URL или data:-URL) на проверку.
+type ModerationRequest struct {
+	Text   string
+	Images []string
+}
+
+// ModerationResult — решение сервиса модерации.
+type ModerationResult struct {
+	Flagged    bool
+	Categories []string // сработавшие категории, например sexual или violence
+}
+
+// Moderator проверяет контент на нарушения правил.
+type Moderator interface {
+	Moderate(req ModerationRequest) (ModerationResult, error)
+}
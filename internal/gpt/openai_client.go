@@ -0,0 +1,319 @@
+package gpt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// requestTimeout ограничивает один запрос к провайдеру: генерация картинок бывает долгой.
+const requestTimeout = 2 * time.Minute
+
+// Client работает с любым OpenAI-совместимым API: OpenAI, Groq, Ollama, LM Studio.
+// Реализует TextGenerator, TextStreamer, ImageGenerator и Moderator.
+type Client struct {
+	BaseURL   string // например, https://api.openai.com/v1
+	Model     string
+	APIKey    string // может быть пустым для локального сервера
+	ImageSize string // только для генерации картинок
+	JSONMode  string // как передавать TextRequest.Schema; пусто — как JSONModeText
+	http      *http.Client
+}
+
+// NewClient создает клиент для OpenAI-совместимого API.
+func NewClient(baseURL, model, apiKey string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Model:   model,
+		APIKey:  apiKey,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature float64       `json:"temperature"`
+	Stream      bool          `json:"stream,omitempty"`
+	// StreamOptions просит прислать расход токенов последним фрагментом потока
+	StreamOptions  *streamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *jsonSchemaFormat `json:"json_schema,omitempty"`
+}
+
+type jsonSchemaFormat struct {
+	Name   string                 `json:"name"`
+	Strict bool                   `json:"strict"`
+	Schema map[string]interface{} `json:"schema"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *TokenUsage `json:"usage"`
+}
+
+// chatChunk — один фрагмент потокового ответа /chat/completions.
+type chatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *TokenUsage `json:"usage"`
+	// Groq присылает расход в своем поле
+	XGroq *struct {
+		Usage *TokenUsage `json:"usage"`
+	} `json:"x_groq"`
+}
+
+type imageRequest struct {
+	Model  string `json:"model,omitempty"`
+	Prompt string `json:"prompt"`
+	N      int    `json:"n"`
+	Size   string `json:"size,omitempty"`
+}
+
+type imageResponse struct {
+	Created int64 `json:"created"`
+	Data    []struct {
+		URL string `json:"url"`
+	} `json:"data"`
+}
+
+type moderationRequest struct {
+	Model string            `json:"model"`
+	Input []moderationInput `json:"input"`
+}
+
+// moderationInput — часть мультимодального запроса /moderations.
+type moderationInput struct {
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	ImageURL *moderationURL `json:"image_url,omitempty"`
+}
+
+type moderationURL struct {
+	URL string `json:"url"`
+}
+
+type moderationResponse struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+func (c *Client) chatRequest(req TextRequest) chatRequest {
+	var messages []chatMessage
+	if req.System != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, chatMessage{Role: "user", Content: req.Prompt})
+	body := chatRequest{
+		Model:       c.Model,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	}
+	// Модели без поддержки схемы отвечают ошибкой на весь запрос, поэтому схема
+	// передается только тем, кто ее понимает; ответ все равно проверяет вызывающий
+	if req.Schema != nil {
+		switch c.JSONMode {
+		case JSONModeSchema:
+			body.ResponseFormat = &responseFormat{
+				Type:       "json_schema",
+				JSONSchema: &jsonSchemaFormat{Name: req.Schema.Name, Strict: true, Schema: req.Schema.Schema},
+			}
+		case JSONModeObject:
+			body.ResponseFormat = &responseFormat{Type: "json_object"}
+		}
+	}
+	return body
+}
+
+// GenerateText запрашивает ответ модели через /chat/completions.
+func (c *Client) GenerateText(req TextRequest) (string, error) {
+	var res chatResponse
+	if err := c.post("/chat/completions", c.chatRequest(req), &res); err != nil {
+		return "", err
+	}
+	if len(res.Choices) == 0 {
+		return "", fmt.Errorf("пустой ответ от модели %s", c.Model)
+	}
+	if req.Usage != nil && res.Usage != nil {
+		*req.Usage = *res.Usage
+	}
+	return res.Choices[0].Message.Content, nil
+}
+
+// StreamText запрашивает ответ модели потоком (server-sent events) и передает
+// фрагменты в onDelta по мере их прихода.
+func (c *Client) StreamText(req TextRequest, onDelta func(delta string)) (string, error) {
+	body := c.chatRequest(req)
+	body.Stream = true
+	if req.Usage != nil {
+		body.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+	resp, err := c.send("/chat/completions", body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // пустые строки-разделители и комментарии SSE
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return text.String(), fmt.Errorf("ошибка декодирования фрагмента: %w", err)
+		}
+		if req.Usage != nil {
+			if chunk.Usage != nil {
+				*req.Usage = *chunk.Usage
+			} else if chunk.XGroq != nil && chunk.XGroq.Usage != nil {
+				*req.Usage = *chunk.XGroq.Usage
+			}
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		delta := chunk.Choices[0].Delta.Content
+		text.WriteString(delta)
+		if onDelta != nil {
+			onDelta(delta)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return text.String(), fmt.Errorf("ошибка чтения потока: %w", err)
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("пустой ответ от модели %s", c.Model)
+	}
+	return text.String(), nil
+}
+
+// GenerateImage генерирует картинку через /images/generations и возвращает ее URL.
+func (c *Client) GenerateImage(req ImageRequest) (string, error) {
+	var res imageResponse
+	if err := c.post("/images/generations", imageRequest{
+		Model:  c.Model,
+		Prompt: req.Prompt,
+		N:      1,
+		Size:   c.ImageSize,
+	}, &res); err != nil {
+		return "", err
+	}
+	if len(res.Data) == 0 {
+		return "", fmt.Errorf("пустой ответ от генерации изображения")
+	}
+	return res.Data[0].URL, nil
+}
+
+// Moderate проверяет текст и картинки через /moderations. Категории
+// собираются со всех результатов ответа.
+func (c *Client) Moderate(req ModerationRequest) (ModerationResult, error) {
+	body := moderationRequest{Model: c.Model}
+	if req.Text != "" {
+		body.Input = append(body.Input, moderationInput{Type: "text", Text: req.Text})
+	}
+	for _, url := range req.Images {
+		body.Input = append(body.Input, moderationInput{Type: "image_url", ImageURL: &moderationURL{URL: url}})
+	}
+	if len(body.Input) == 0 {
+		return ModerationResult{}, nil
+	}
+
+	var res moderationResponse
+	if err := c.post("/moderations", body, &res); err != nil {
+		return ModerationResult{}, err
+	}
+	if len(res.Results) == 0 {
+		return ModerationResult{}, fmt.Errorf("пустой ответ от модерации %s", c.Model)
+	}
+	var result ModerationResult
+	seen := make(map[string]bool)
+	for _, r := range res.Results {
+		result.Flagged = result.Flagged || r.Flagged
+		for category, hit := range r.Categories {
+			if hit && !seen[category] {
+				seen[category] = true
+				result.Categories = append(result.Categories, category)
+			}
+		}
+	}
+	sort.Strings(result.Categories)
+	return result, nil
+}
+
+func (c *Client) post(path string, body, out interface{}) error {
+	resp, err := c.send(path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("ошибка декодирования ответа: %w", err)
+	}
+	return nil
+}
+
+// send выполняет POST-запрос и возвращает ответ со статусом 200; тело ответа
+// закрывает вызывающий.
+func (c *Client) send(path string, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка маршалинга запроса: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.BaseURL+path, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("неожиданный статус %d: %s", resp.StatusCode, string(body))
+	}
+	return resp, nil
+}
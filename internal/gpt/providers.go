@@ -0,0 +1,142 @@
+package gpt
+
+import (
+	"fmt"
+	"lady/config"
+	"strings"
+	"time"
+)
+
+// provider — адрес и модели провайдера по умолчанию.
+type provider struct {
+	baseURL    string
+	textModel  string
+	imageModel string // пусто, если провайдер не умеет генерировать картинки
+	// moderationModel пуст, если у провайдера нет /moderations
+	moderationModel string
+	// jsonMode — что текстовая модель по умолчанию понимает в response_format
+	jsonMode string
+	needsKey bool
+}
+
+var providers = map[string]provider{
+	// gpt-4 не знает response_format; для gpt-4o укажите TEXT_JSON_MODE=schema
+	"openai": {baseURL: "https://api.openai.com/v1", textModel: "gpt-4", imageModel: "dall-e-2", moderationModel: "omni-moderation-latest", jsonMode: JSONModeText, needsKey: true},
+	"groq":   {baseURL: "https://api.groq.com/openai/v1", textModel: "llama-3.3-70b-versatile", jsonMode: JSONModeObject, needsKey: true},
+	// Ollama; для LM Studio укажите TEXT_BASE_URL=http://localhost:1234/v1
+	"local": {baseURL: "http://localhost:11434/v1", textModel: "llama3.1", jsonMode: JSONModeText},
+}
+
+// Способы попросить у модели JSON.
+const (
+	JSONModeSchema = "schema" // response_format json_schema со strict: модель следует схеме
+	JSONModeObject = "object" // response_format json_object: любой JSON-объект
+	JSONModeText   = "text"   // без response_format: формат задает только инструкция
+)
+
+// jsonMode возвращает способ запроса JSON из настроек или по умолчанию провайдера.
+func jsonMode(cfg config.ProviderConfig) (string, error) {
+	switch cfg.JSONMode {
+	case "":
+		return providers[providerName(cfg)].jsonMode, nil
+	case JSONModeSchema, JSONModeObject, JSONModeText:
+		return cfg.JSONMode, nil
+	}
+	return "", fmt.Errorf("неизвестный JSON_MODE %q (schema, object, text)", cfg.JSONMode)
+}
+
+const defaultImageSize = "512x512"
+
+// providerName возвращает имя провайдера с учетом значения по умолчанию.
+func providerName(cfg config.ProviderConfig) string {
+	if cfg.Provider == "" {
+		return "openai"
+	}
+	return cfg.Provider
+}
+
+// Виды моделей провайдера.
+const (
+	kindText       = "text"
+	kindImage      = "image"
+	kindModeration = "moderation"
+)
+
+// resolve подставляет адрес и модель провайдера по умолчанию. kind выбирает,
+// какая модель нужна: для текста, картинок или модерации.
+func resolve(cfg config.ProviderConfig, kind string) (string, string, error) {
+	name := providerName(cfg)
+	p, ok := providers[name]
+	if !ok {
+		return "", "", fmt.Errorf("неизвестный провайдер %q (openai, groq, local)", name)
+	}
+
+	baseURL, model := cfg.BaseURL, cfg.Model
+	if baseURL == "" {
+		baseURL = p.baseURL
+	}
+	if model == "" {
+		switch kind {
+		case kindImage:
+			model = p.imageModel
+		case kindModeration:
+			model = p.moderationModel
+		default:
+			model = p.textModel
+		}
+	}
+	if model == "" {
+		return "", "", fmt.Errorf("у провайдера %s нет модели по умолчанию для %s, укажите %s_MODEL", name, kind, strings.ToUpper(kind))
+	}
+	if p.needsKey && cfg.APIKey == "" {
+		return "", "", fmt.Errorf("не задан API-ключ провайдера %s", name)
+	}
+	return baseURL, model, nil
+}
+
+// NewTextGenerator создает генератор текста по настройкам провайдера. Если задан
+// cache и ttl > 0, одинаковые запросы в пределах ttl отдаются из кэша.
+func NewTextGenerator(cfg config.ProviderConfig, cache Cache, ttl time.Duration) (TextGenerator, error) {
+	baseURL, model, err := resolve(cfg, kindText)
+	if err != nil {
+		return nil, err
+	}
+	client := NewClient(baseURL, model, cfg.APIKey)
+	if client.JSONMode, err = jsonMode(cfg); err != nil {
+		return nil, err
+	}
+	if cache == nil || ttl <= 0 {
+		return client, nil
+	}
+	scope := fmt.Sprintf("%s|%s|%s", providerName(cfg), baseURL, model)
+	return &cachedText{responseCache: responseCache{cache: cache, scope: scope, ttl: ttl}, next: client}, nil
+}
+
+// NewImageGenerator создает генератор картинок по настройкам провайдера. Кэш
+// работает так же, как у NewTextGenerator. Ссылки генератора живут около часа,
+// поэтому по ссылке из кэша картинку нужно брать из уже скачанных.
+func NewImageGenerator(cfg config.ProviderConfig, cache Cache, ttl time.Duration) (ImageGenerator, error) {
+	baseURL, model, err := resolve(cfg, kindImage)
+	if err != nil {
+		return nil, err
+	}
+	client := NewClient(baseURL, model, cfg.APIKey)
+	client.ImageSize = cfg.ImageSize
+	if client.ImageSize == "" {
+		client.ImageSize = defaultImageSize
+	}
+	if cache == nil || ttl <= 0 {
+		return client, nil
+	}
+	scope := fmt.Sprintf("%s|%s|%s|%s", providerName(cfg), baseURL, model, client.ImageSize)
+	return &cachedImages{responseCache: responseCache{cache: cache, scope: scope, ttl: ttl}, next: client}, nil
+}
+
+// NewModerator создает клиент модерации по настройкам провайдера.
+func NewModerator(cfg config.ProviderConfig) (Moderator, error) {
+	baseURL, model, err := resolve(cfg, kindModeration)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(baseURL, model, cfg.APIKey), nil
+}
@@ -0,0 +1,116 @@
+package media
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	downloadTimeout = time.Minute
+	// maxFileSize ограничивает скачиваемый файл: Telegram принимает фото до 10 МБ.
+	maxFileSize = 10 << 20
+)
+
+// File — файл, сохраненный в хранилище.
+type File struct {
+	Path     string
+	MimeType string
+	Size     int64
+}
+
+// Store хранит картинки на диске. Имя файла — хеш содержимого, поэтому
+// одинаковые картинки не дублируются.
+type Store struct {
+	dir  string
+	http *http.Client
+}
+
+// NewStore создает хранилище в каталоге dir, создавая его при необходимости.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога медиа %s: %w", dir, err)
+	}
+	return &Store{dir: dir, http: &http.Client{Timeout: downloadTimeout}}, nil
+}
+
+// Download скачивает картинку по URL и сохраняет ее в хранилище.
+func (s *Store) Download(url string) (File, error) {
+	resp, err := s.http.Get(url)
+	if err != nil {
+		return File{}, fmt.Errorf("ошибка загрузки картинки: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return File{}, fmt.Errorf("неверный статус ответа: %d", resp.StatusCode)
+	}
+	mimeType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mimeType == "application/octet-stream" {
+		// Файловый сервер Telegram не всегда сообщает тип — определим по содержимому
+		mimeType = ""
+	}
+	if mimeType != "" && !strings.HasPrefix(mimeType, "image/") {
+		return File{}, fmt.Errorf("по адресу не картинка, а %s", mimeType)
+	}
+	return s.Save(resp.Body, mimeType)
+}
+
+// Save сохраняет содержимое r в хранилище. Пустой mimeType определяется по содержимому.
+func (s *Store) Save(r io.Reader, mimeType string) (File, error) {
+	br := bufio.NewReader(r)
+	if mimeType == "" {
+		head, _ := br.Peek(512)
+		mimeType = http.DetectContentType(head)
+		if !strings.HasPrefix(mimeType, "image/") {
+			return File{}, fmt.Errorf("файл не похож на картинку: %s", mimeType)
+		}
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return File{}, fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(br, maxFileSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return File{}, fmt.Errorf("ошибка сохранения файла: %w", err)
+	}
+	if size > maxFileSize {
+		return File{}, fmt.Errorf("файл больше %d МБ", maxFileSize>>20)
+	}
+
+	path := filepath.Join(s.dir, hex.EncodeToString(hash.Sum(nil))+extension(mimeType))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return File{}, fmt.Errorf("ошибка сохранения файла: %w", err)
+	}
+	return File{Path: path, MimeType: mimeType, Size: size}, nil
+}
+
+// extension подбирает расширение файла по MIME-типу.
+func extension(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	}
+	return ""
+}
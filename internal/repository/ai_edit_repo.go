@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lady/internal/domain"
+)
+
+// CreateAIEdit сохраняет предложенную моделью правку и возвращает ее ID.
+func (r *TopicRepository) CreateAIEdit(e domain.AIEdit) (int64, error) {
+	res, err := r.db.Exec(
+		`INSERT INTO ai_edits (post_id, instruction, original, revised, status) VALUES (?, ?, ?, ?, ?)`,
+		e.PostID, e.Instruction, e.Original, e.Revised, string(domain.AIEditProposed),
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения AI-правки поста %d: %v", e.PostID, err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// GetAIEdit возвращает AI-правку по ID.
+func (r *TopicRepository) GetAIEdit(id int64) (domain.AIEdit, error) {
+	var e domain.AIEdit
+	var status string
+	var createdAt sql.NullString
+	err := r.db.QueryRow(
+		`SELECT id, post_id, instruction, original, revised, status, created_at FROM ai_edits WHERE id = ?`, id,
+	).Scan(&e.ID, &e.PostID, &e.Instruction, &e.Original, &e.Revised, &status, &createdAt)
+	if err == sql.ErrNoRows {
+		return domain.AIEdit{}, fmt.Errorf("ai edit %d not found", id)
+	}
+	if err != nil {
+		return domain.AIEdit{}, err
+	}
+	e.Status = domain.AIEditStatus(status)
+	e.CreatedAt = parseUTC(createdAt)
+	return e, nil
+}
+
+// ApplyAIEdit переводит правку из статуса from в статус to и заменяет текст
+// поста oldText на newText в одной транзакции. Текст меняется, только если у
+// поста все еще oldText: правка не затирает то, что редактор изменил после нее.
+// Одинаковые oldText и newText меняют только статус.
+func (r *TopicRepository) ApplyAIEdit(e domain.AIEdit, from, to domain.AIEditStatus, oldText, newText string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE ai_edits SET status = ? WHERE id = ? AND status = ?`, string(to), e.ID, string(from))
+	if err != nil {
+		log.Printf("Ошибка смены статуса AI-правки %d: %v", e.ID, err)
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("ai edit %d not found or changed concurrently", e.ID)
+	}
+
+	if oldText != newText {
+		res, err := tx.Exec(
+			`UPDATE posts SET text = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND text = ?`,
+			newText, e.PostID, oldText,
+		)
+		if err != nil {
+			log.Printf("Ошибка применения AI-правки %d к посту %d: %v", e.ID, e.PostID, err)
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return fmt.Errorf("post %d text changed since ai edit %d", e.PostID, e.ID)
+		}
+		if _, err := tx.Exec(
+			`UPDATE post_variants SET text = ? WHERE id = (SELECT variant_id FROM posts WHERE id = ?)`, newText, e.PostID,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *TopicRepository) SavePendingAIEdit(chatID, postID int64) error {
+	_, err := r.db.Exec(`INSERT OR REPLACE INTO pending_ai_edits (chat_id, post_id) VALUES (?, ?)`, chatID, postID)
+	if err != nil {
+		log.Printf("Ошибка сохранения ожидания AI-правки для chatID %d: %v", chatID, err)
+	}
+	return err
+}
+
+func (r *TopicRepository) GetPendingAIEdit(chatID int64) (postID int64, err error) {
+	err = r.db.QueryRow(`SELECT post_id FROM pending_ai_edits WHERE chat_id = ?`, chatID).Scan(&postID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("no pending ai edit for chatID %d", chatID)
+	}
+	return postID, err
+}
+
+func (r *TopicRepository) ClearPendingAIEdit(chatID int64) error {
+	_, err := r.db.Exec(`DELETE FROM pending_ai_edits WHERE chat_id = ?`, chatID)
+	if err != nil {
+		log.Printf("Ошибка очистки ожидания AI-правки для chatID %d: %v", chatID, err)
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// CacheRepository хранит кэш ответов провайдеров генерации.
+type CacheRepository struct {
+	db *sql.DB
+}
+
+// NewCacheRepository создает репозиторий кэша поверх открытой базы.
+func NewCacheRepository(db *sql.DB) *CacheRepository {
+	return &CacheRepository{db: db}
+}
+
+// Get возвращает ответ по ключу, если его срок еще не истек.
+func (r *CacheRepository) Get(key string) (string, bool, error) {
+	var value string
+	err := r.db.QueryRow(
+		`SELECT value FROM response_cache WHERE key = ? AND expires_at > ?`,
+		key, time.Now().UTC().Format(dbTimeLayout),
+	).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// Put сохраняет ответ до expiresAt и заодно удаляет просроченные записи.
+func (r *CacheRepository) Put(key, value string, expiresAt time.Time) error {
+	now := time.Now().UTC().Format(dbTimeLayout)
+	if _, err := r.db.Exec(`DELETE FROM response_cache WHERE expires_at <= ?`, now); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(
+		`INSERT INTO response_cache (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		key, value, expiresAt.UTC().Format(dbTimeLayout),
+	)
+	return err
+}
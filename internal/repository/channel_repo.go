@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lady/internal/domain"
+)
+
+// ChannelRepository хранит реестр каналов и настройки редакторов.
+type ChannelRepository struct {
+	db *sql.DB
+}
+
+// NewChannelRepository создает репозиторий каналов поверх открытой базы.
+func NewChannelRepository(db *sql.DB) *ChannelRepository {
+	return &ChannelRepository{db: db}
+}
+
+// Save добавляет канал или обновляет его название.
+func (r *ChannelRepository) Save(ch domain.Channel) error {
+	_, err := r.db.Exec(
+		`INSERT INTO channels (id, title, added_by) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET title = excluded.title`,
+		ch.ID, ch.Title, ch.AddedBy,
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения канала %d: %v", ch.ID, err)
+		return err
+	}
+	return nil
+}
+
+// Get возвращает канал по ID.
+func (r *ChannelRepository) Get(id int64) (domain.Channel, error) {
+	var ch domain.Channel
+	var createdAt sql.NullString
+	err := r.db.QueryRow(`SELECT id, title, added_by, created_at FROM channels WHERE id = ?`, id).
+		Scan(&ch.ID, &ch.Title, &ch.AddedBy, &createdAt)
+	if err == sql.ErrNoRows {
+		return domain.Channel{}, fmt.Errorf("канал %d не привязан", id)
+	}
+	if err != nil {
+		return domain.Channel{}, err
+	}
+	ch.CreatedAt = parseUTC(createdAt)
+	return ch, nil
+}
+
+// List возвращает все привязанные каналы.
+func (r *ChannelRepository) List() ([]domain.Channel, error) {
+	rows, err := r.db.Query(`SELECT id, title, added_by, created_at FROM channels ORDER BY title, id`)
+	if err != nil {
+		log.Printf("Ошибка получения каналов: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []domain.Channel
+	for rows.Next() {
+		var ch domain.Channel
+		var createdAt sql.NullString
+		if err := rows.Scan(&ch.ID, &ch.Title, &ch.AddedBy, &createdAt); err != nil {
+			return nil, err
+		}
+		ch.CreatedAt = parseUTC(createdAt)
+		channels = append(channels, ch)
+	}
+	return channels, rows.Err()
+}
+
+// Delete удаляет канал из реестра. Внешние ключи сбрасывают его как канал по
+// умолчанию и удаляют выбранную для него персону.
+func (r *ChannelRepository) Delete(id int64) error {
+	res, err := r.db.Exec(`DELETE FROM channels WHERE id = ?`, id)
+	if err != nil {
+		log.Printf("Ошибка удаления канала %d: %v", id, err)
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("канал %d не привязан", id)
+	}
+	return nil
+}
+
+// CountScheduled возвращает число постов, стоящих в очереди в канал.
+func (r *ChannelRepository) CountScheduled(id int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(
+		`SELECT COUNT(*) FROM posts WHERE channel_id = ? AND status IN (?, ?)`,
+		id, string(domain.PostScheduled), string(domain.PostPublishing),
+	).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lady/internal/domain"
+)
+
+// insertImages сохраняет картинки поста по порядку.
+func insertImages(tx *sql.Tx, postID int64, images []domain.PostImage) error {
+	for i, img := range images {
+		if _, err := tx.Exec(
+			`INSERT INTO post_images (post_id, position, url, prompt, media_id) VALUES (?, ?, ?, ?, ?)`,
+			postID, i, img.URL, img.Prompt, nullID(img.MediaID),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplacePostImages заменяет все картинки поста.
+func (r *TopicRepository) ReplacePostImages(postID int64, images []domain.PostImage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM post_images WHERE post_id = ?`, postID); err != nil {
+		log.Printf("Ошибка удаления картинок поста %d: %v", postID, err)
+		return err
+	}
+	if err := insertImages(tx, postID, images); err != nil {
+		log.Printf("Ошибка сохранения картинок поста %d: %v", postID, err)
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE posts SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, postID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// postImages возвращает картинки поста в порядке показа.
+func (r *TopicRepository) postImages(postID int64) ([]domain.PostImage, error) {
+	rows, err := r.db.Query(
+		`SELECT i.url, i.prompt, i.media_id, COALESCE(m.path, ''), COALESCE(m.file_id, '')
+		FROM post_images i LEFT JOIN media m ON m.id = i.media_id
+		WHERE i.post_id = ? ORDER BY i.position, i.id`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []domain.PostImage
+	for rows.Next() {
+		var img domain.PostImage
+		var mediaID sql.NullInt64
+		if err := rows.Scan(&img.URL, &img.Prompt, &mediaID, &img.Path, &img.FileID); err != nil {
+			return nil, err
+		}
+		img.MediaID = mediaID.Int64
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
+
+// attachImages загружает картинки в уже прочитанные посты.
+func (r *TopicRepository) attachImages(posts []domain.Post) error {
+	for i := range posts {
+		images, err := r.postImages(posts[i].ID)
+		if err != nil {
+			log.Printf("Ошибка получения картинок поста %d: %v", posts[i].ID, err)
+			return err
+		}
+		posts[i].Images = images
+	}
+	return nil
+}
+
+func (r *TopicRepository) SavePendingImagePrompt(chatID, postID, mediaID int64, messageID int) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO pending_image_prompts (chat_id, post_id, media_id, message_id) VALUES (?, ?, ?, ?)`,
+		chatID, postID, mediaID, messageID,
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения ожидания описания картинки для chatID %d: %v", chatID, err)
+	}
+	return err
+}
+
+func (r *TopicRepository) GetPendingImagePrompt(chatID int64) (postID, mediaID int64, messageID int, err error) {
+	err = r.db.QueryRow(
+		`SELECT post_id, media_id, message_id FROM pending_image_prompts WHERE chat_id = ?`, chatID,
+	).Scan(&postID, &mediaID, &messageID)
+	if err == sql.ErrNoRows {
+		return 0, 0, 0, fmt.Errorf("no pending image prompt for chatID %d", chatID)
+	}
+	return postID, mediaID, messageID, err
+}
+
+func (r *TopicRepository) ClearPendingImagePrompt(chatID int64) error {
+	_, err := r.db.Exec(`DELETE FROM pending_image_prompts WHERE chat_id = ?`, chatID)
+	if err != nil {
+		log.Printf("Ошибка очистки ожидания описания картинки для chatID %d: %v", chatID, err)
+	}
+	return err
+}
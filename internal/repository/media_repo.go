@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lady/internal/domain"
+)
+
+// MediaRepository хранит метаданные файлов из хранилища картинок.
+type MediaRepository struct {
+	db *sql.DB
+}
+
+// NewMediaRepository создает репозиторий медиа поверх открытой базы.
+func NewMediaRepository(db *sql.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+// Save сохраняет файл и возвращает его ID. Файл с тем же путем уже лежит в
+// хранилище — тогда возвращается ID существующей записи.
+func (r *MediaRepository) Save(m domain.Media) (int64, error) {
+	_, err := r.db.Exec(
+		`INSERT INTO media (path, source_url, mime_type, size) VALUES (?, ?, ?, ?)
+		ON CONFLICT (path) DO NOTHING`,
+		m.Path, m.SourceURL, m.MimeType, m.Size,
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения медиа %s: %v", m.Path, err)
+		return 0, err
+	}
+	var id int64
+	if err := r.db.QueryRow(`SELECT id FROM media WHERE path = ?`, m.Path).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+const mediaColumns = `id, path, source_url, mime_type, size, file_id, created_at`
+
+func scanMedia(row rowScanner) (domain.Media, error) {
+	var m domain.Media
+	var createdAt sql.NullString
+	if err := row.Scan(&m.ID, &m.Path, &m.SourceURL, &m.MimeType, &m.Size, &m.FileID, &createdAt); err != nil {
+		return domain.Media{}, err
+	}
+	m.CreatedAt = parseUTC(createdAt)
+	return m, nil
+}
+
+// Get возвращает файл по ID.
+func (r *MediaRepository) Get(id int64) (domain.Media, error) {
+	m, err := scanMedia(r.db.QueryRow(`SELECT `+mediaColumns+` FROM media WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return domain.Media{}, fmt.Errorf("медиа %d не найдено", id)
+	}
+	return m, err
+}
+
+// FindBySource возвращает файл, скачанный по адресу url; ok = false, если такого нет.
+func (r *MediaRepository) FindBySource(url string) (m domain.Media, ok bool, err error) {
+	m, err = scanMedia(r.db.QueryRow(`SELECT `+mediaColumns+` FROM media WHERE source_url = ? ORDER BY id DESC LIMIT 1`, url))
+	if err == sql.ErrNoRows {
+		return domain.Media{}, false, nil
+	}
+	if err != nil {
+		return domain.Media{}, false, err
+	}
+	return m, true, nil
+}
+
+// SetFileID запоминает file_id Telegram для файла.
+func (r *MediaRepository) SetFileID(id int64, fileID string) error {
+	_, err := r.db.Exec(`UPDATE media SET file_id = ? WHERE id = ?`, fileID, id)
+	if err != nil {
+		log.Printf("Ошибка сохранения file_id медиа %d: %v", id, err)
+	}
+	return err
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Migration описывает одну up-миграцию схемы.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Open открывает базу SQLite с проверкой внешних ключей. Схему она не трогает —
+// для этого есть Migrate.
+func Open(dbPath string) (*sql.DB, error) {
+	// Без прагмы SQLite не выполняет ON DELETE из миграций. Прагма в адресе
+	// применяется к каждому новому соединению, а не только к первому
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", dbPath+sep+"_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы %s: %w", dbPath, err)
+	}
+	// SQLite не любит параллельных писателей, поэтому держим одно соединение.
+	db.SetMaxOpenConns(1)
+	var foreignKeys int
+	if err := db.QueryRow(`PRAGMA foreign_keys`).Scan(&foreignKeys); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка подключения к базе %s: %w", dbPath, err)
+	}
+	if foreignKeys != 1 {
+		db.Close()
+		return nil, fmt.Errorf("база %s: не удалось включить проверку внешних ключей", dbPath)
+	}
+	return db, nil
+}
+
+// Migrations возвращает встроенные миграции, отсортированные по версии.
+func Migrations() ([]Migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, file := range files {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("некорректное имя миграции %s: ожидается NNNN_name.sql", file)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("некорректная версия миграции %s", file)
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("версия %d повторяется в миграциях %s и %s", version, other, file)
+		}
+		seen[version] = file
+
+		body, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(body)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// SchemaVersion возвращает номер последней примененной миграции (0 для пустой базы).
+func SchemaVersion(db *sql.DB) (int, error) {
+	if err := ensureSchemaVersionTable(db); err != nil {
+		return 0, err
+	}
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("ошибка чтения версии схемы: %w", err)
+	}
+	return version, nil
+}
+
+// Migrate применяет все миграции новее текущей версии схемы.
+// Каждая миграция выполняется в своей транзакции вместе с записью в schema_version.
+func Migrate(db *sql.DB) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return applied, err
+		}
+		log.Printf("Применена миграция %04d_%s", m.Version, m.Name)
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+func ensureSchemaVersionTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("ошибка создания таблицы schema_version: %w", err)
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return fmt.Errorf("ошибка миграции %04d_%s: %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.Version, m.Name, time.Now().UTC().Format("2006-01-02 15:04:05"),
+	); err != nil {
+		return fmt.Errorf("ошибка записи версии %d: %w", m.Version, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateFromEmpty(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "lady.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations: %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migration %04d_%s: want version %d, versions must go without gaps", m.Version, m.Name, i+1)
+		}
+	}
+
+	applied, err := Migrate(db)
+	if err != nil {
+		t.Fatalf("Migrate on empty database: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("applied %d migrations, want %d", len(applied), len(migrations))
+	}
+	version, err := SchemaVersion(db)
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	if last := migrations[len(migrations)-1].Version; version != last {
+		t.Errorf("schema version = %d, want %d", version, last)
+	}
+
+	applied, err = Migrate(db)
+	if err != nil {
+		t.Fatalf("second Migrate: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Migrate applied %d migrations, want none", len(applied))
+	}
+
+	rows, err := db.Query(`PRAGMA foreign_key_check`)
+	if err != nil {
+		t.Fatalf("foreign_key_check: %v", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		t.Error("foreign_key_check reports violations on a fresh schema")
+	}
+	var integrity string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&integrity); err != nil || integrity != "ok" {
+		t.Errorf("integrity_check = %q, %v", integrity, err)
+	}
+}
+
+func TestOpenEnforcesForeignKeys(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "lady.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	if _, err := Migrate(db); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO post_variants (post_id, text) VALUES (999999, 'сирота')`); err == nil {
+		t.Error("insert with a missing parent succeeded, foreign keys are not enforced")
+	} else if !strings.Contains(err.Error(), "FOREIGN KEY") {
+		t.Errorf("insert with a missing parent failed for another reason: %v", err)
+	}
+}
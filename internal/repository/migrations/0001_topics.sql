@@ -0,0 +1,5 @@
+-- Исходная таблица тем. IF NOT EXISTS нужен для баз, созданных до появления миграций.
+CREATE TABLE IF NOT EXISTS topics (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT
+);
@@ -0,0 +1,18 @@
+-- Черновики, очередь публикации и состояние диалога.
+CREATE TABLE IF NOT EXISTS pending_posts (
+	chat_id INTEGER PRIMARY KEY,
+	text TEXT NOT NULL,
+	img1 TEXT NOT NULL DEFAULT '',
+	img2 TEXT NOT NULL DEFAULT '',
+	publish_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS pending_edits (
+	chat_id INTEGER PRIMARY KEY,
+	text TEXT NOT NULL,
+	message_id INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pending_schedule (
+	chat_id INTEGER PRIMARY KEY
+);
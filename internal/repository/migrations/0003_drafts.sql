@@ -0,0 +1,32 @@
+-- Черновики получают собственный ID: в одном чате может быть несколько постов в очереди.
+CREATE TABLE drafts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	chat_id INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	img1 TEXT NOT NULL DEFAULT '',
+	img2 TEXT NOT NULL DEFAULT '',
+	publish_at TEXT,
+	created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_drafts_chat ON drafts (chat_id);
+CREATE INDEX idx_drafts_publish_at ON drafts (publish_at);
+
+INSERT INTO drafts (chat_id, text, img1, img2, publish_at)
+SELECT chat_id, text, img1, img2, NULLIF(publish_at, '') FROM pending_posts;
+
+DROP TABLE pending_posts;
+
+-- Состояние диалога теперь ссылается на конкретный черновик.
+DROP TABLE pending_edits;
+CREATE TABLE pending_edits (
+	chat_id INTEGER PRIMARY KEY,
+	draft_id INTEGER NOT NULL,
+	message_id INTEGER NOT NULL
+);
+
+DROP TABLE pending_schedule;
+CREATE TABLE pending_schedule (
+	chat_id INTEGER PRIMARY KEY,
+	draft_id INTEGER NOT NULL
+);
@@ -0,0 +1,20 @@
+-- Черновики становятся постами с жизненным циклом, автором, каналом и темой.
+ALTER TABLE drafts RENAME TO posts;
+ALTER TABLE posts RENAME COLUMN chat_id TO author_id;
+
+ALTER TABLE posts ADD COLUMN status TEXT NOT NULL DEFAULT 'draft';
+ALTER TABLE posts ADD COLUMN channel_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN topic_id INTEGER REFERENCES topics (id);
+ALTER TABLE posts ADD COLUMN published_at TEXT;
+ALTER TABLE posts ADD COLUMN updated_at TEXT;
+
+UPDATE posts SET updated_at = created_at;
+UPDATE posts SET status = 'scheduled' WHERE publish_at IS NOT NULL;
+
+DROP INDEX idx_drafts_chat;
+DROP INDEX idx_drafts_publish_at;
+CREATE INDEX idx_posts_author_status ON posts (author_id, status);
+CREATE INDEX idx_posts_status_publish_at ON posts (status, publish_at);
+
+ALTER TABLE pending_edits RENAME COLUMN draft_id TO post_id;
+ALTER TABLE pending_schedule RENAME COLUMN draft_id TO post_id;
@@ -0,0 +1,4 @@
+-- Учет неудачных попыток публикации для повторов с экспоненциальной задержкой.
+ALTER TABLE posts ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN next_attempt_at TEXT;
+ALTER TABLE posts ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,17 @@
+-- Реестр каналов и настройки редакторов.
+CREATE TABLE channels (
+	id INTEGER PRIMARY KEY,
+	title TEXT NOT NULL,
+	added_by INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE users (
+	id INTEGER PRIMARY KEY,
+	default_channel_id INTEGER REFERENCES channels (id) ON DELETE SET NULL
+);
+
+-- Канал, в который бот публиковал до появления реестра, остается доступным,
+-- а посты без канала продолжают уходить в него.
+INSERT INTO channels (id, title) VALUES (-1002848619245, 'Основной канал');
+UPDATE posts SET channel_id = -1002848619245 WHERE channel_id = 0;
@@ -0,0 +1,7 @@
+-- Часовые пояса редакторов. Время публикации теперь хранится в UTC.
+ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT '';
+
+-- До этой миграции publish_at и next_attempt_at хранились по времени
+-- Asia/Novosibirsk, которое с 2016 года постоянно равно UTC+7.
+UPDATE posts SET publish_at = datetime(publish_at, '-7 hours') WHERE publish_at IS NOT NULL AND publish_at != '';
+UPDATE posts SET next_attempt_at = datetime(next_attempt_at, '-7 hours') WHERE next_attempt_at IS NOT NULL AND next_attempt_at != '';
@@ -0,0 +1,35 @@
+-- Персоны: шаблоны промптов с переменными {topic}, {length}, {tone}, {date}.
+CREATE TABLE personas (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	system_prompt TEXT NOT NULL DEFAULT '',
+	template TEXT NOT NULL,
+	tone TEXT NOT NULL DEFAULT '',
+	length INTEGER NOT NULL DEFAULT 1024,
+	created_by INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Активная персона канала; каналы без записи используют первую персону.
+CREATE TABLE channel_personas (
+	channel_id INTEGER PRIMARY KEY REFERENCES channels (id) ON DELETE CASCADE,
+	persona_id INTEGER NOT NULL REFERENCES personas (id) ON DELETE CASCADE
+);
+
+-- Промпт, который раньше был зашит в код, становится первой персоной.
+INSERT INTO personas (name, system_prompt, template, tone, length) VALUES (
+	'копирайтер',
+	'Ты помощник, который пишет креативные и интересные тексты для постов в Телеграм. c максимальным количеством символов 1024 ...',
+	'Ты — креативный копирайтер, который пишет короткие чувственные тексты для постов в Телеграм. ' ||
+	'Стиль — {tone}, будто автор говорит лично с читателем, с лёгкой игрой и флиртом, загадочностью и недосказанностью. ' ||
+	'Используй метафоры, сенсорные детали (взгляд, прикосновение, звук), эмоциональные контрасты. ' ||
+	'Тексты должны быть живыми, с короткими и длинными фразами, создавая ощущение разговора «один на один». ' ||
+	'В начале всегда интригующая фраза-приманка, в середине — эмоциональный пик с игрой образов, в конце — крючок, который заставляет ждать продолжения. ' ||
+	'Эмодзи используй аккуратно, чтобы усиливать настроение, а не просто вставлять. ' ||
+	'Избегай прямых объяснений — передавай чувства через образы и действия. ' ||
+	'Пиши от первого лица, от женского лица, с уверенностью, мягкой провокацией и тайной. ' ||
+	'Сгенерируй текст с длинной {length} символов в таком стиле по теме: {topic}',
+	'интимный',
+	1024
+);
@@ -0,0 +1,15 @@
+-- Варианты текста поста: каждая перегенерация добавляет вариант, а текст поста —
+-- это выбранный вариант.
+CREATE TABLE post_variants (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+	text TEXT NOT NULL,
+	created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_post_variants_post ON post_variants (post_id, id);
+
+ALTER TABLE posts ADD COLUMN variant_id INTEGER REFERENCES post_variants (id);
+
+-- Текущий текст каждого поста становится его первым вариантом.
+INSERT INTO post_variants (post_id, text, created_at) SELECT id, text, created_at FROM posts;
+UPDATE posts SET variant_id = (SELECT v.id FROM post_variants v WHERE v.post_id = posts.id);
@@ -0,0 +1,23 @@
+-- Картинки поста: упорядоченный список вместо колонок img1/img2. Для
+-- сгенерированных картинок хранится описание, по которому их рисовали.
+CREATE TABLE post_images (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	url TEXT NOT NULL,
+	prompt TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_post_images_post ON post_images (post_id, position);
+
+INSERT INTO post_images (post_id, position, url) SELECT id, 0, img1 FROM posts WHERE img1 != '';
+INSERT INTO post_images (post_id, position, url) SELECT id, 1, img2 FROM posts WHERE img2 != '';
+
+ALTER TABLE posts DROP COLUMN img1;
+ALTER TABLE posts DROP COLUMN img2;
+
+-- Стиль и число картинок задаются персоной канала: до 10, как в медиа-группе.
+ALTER TABLE personas ADD COLUMN image_style TEXT NOT NULL DEFAULT '';
+ALTER TABLE personas ADD COLUMN image_count INTEGER NOT NULL DEFAULT 2;
+
+UPDATE personas SET image_style = 'cinematic film photography, soft warm light, shallow depth of field, intimate mood, muted pastel palette'
+WHERE name = 'копирайтер';
@@ -0,0 +1,13 @@
+-- Хранилище картинок: файлы лежат на диске, здесь — их метаданные и file_id
+-- Telegram, чтобы не загружать один файл повторно.
+CREATE TABLE media (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	path TEXT NOT NULL UNIQUE,
+	source_url TEXT NOT NULL DEFAULT '',
+	mime_type TEXT NOT NULL DEFAULT '',
+	size INTEGER NOT NULL DEFAULT 0,
+	file_id TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE post_images ADD COLUMN media_id INTEGER REFERENCES media (id);
@@ -0,0 +1,8 @@
+-- Чат, который вводит новое описание картинки: пост, файл картинки и
+-- сообщение превью, которое нужно обновить.
+CREATE TABLE pending_image_prompts (
+	chat_id INTEGER PRIMARY KEY,
+	post_id INTEGER NOT NULL,
+	media_id INTEGER NOT NULL,
+	message_id INTEGER NOT NULL
+);
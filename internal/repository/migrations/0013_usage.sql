@@ -0,0 +1,14 @@
+-- Расход токенов и картинок по редакторам и каналам для квот и отчета /usage.
+CREATE TABLE usage (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	channel_id INTEGER NOT NULL DEFAULT 0,
+	kind TEXT NOT NULL,
+	prompt_tokens INTEGER NOT NULL DEFAULT 0,
+	completion_tokens INTEGER NOT NULL DEFAULT 0,
+	images INTEGER NOT NULL DEFAULT 0,
+	cost REAL NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_usage_user_created ON usage (user_id, created_at);
+CREATE INDEX idx_usage_created ON usage (created_at);
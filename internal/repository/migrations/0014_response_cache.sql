@@ -0,0 +1,7 @@
+-- Кэш ответов провайдеров генерации по хэшу запроса.
+CREATE TABLE response_cache (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL,
+	expires_at TEXT NOT NULL
+);
+CREATE INDEX idx_response_cache_expires ON response_cache (expires_at);
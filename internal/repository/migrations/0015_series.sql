@@ -0,0 +1,13 @@
+-- Серии: посты, каждый из которых продолжает историю предыдущих.
+CREATE TABLE series (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	created_by INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE posts ADD COLUMN series_id INTEGER REFERENCES series (id);
+CREATE INDEX idx_posts_series ON posts (series_id, status, published_at);
+
+-- Серия, в которой редактор сейчас пишет; NULL — посты вне серий.
+ALTER TABLE users ADD COLUMN series_id INTEGER REFERENCES series (id);
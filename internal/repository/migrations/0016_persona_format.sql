@@ -0,0 +1,3 @@
+-- Персона может просить у модели пост строгим JSON: приманка, текст, крючок,
+-- хэштеги, описания картинок и время публикации.
+ALTER TABLE personas ADD COLUMN structured INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,18 @@
+-- AI-правки: текст поста до и после правки по инструкции редактора. Правка
+-- хранится, пока редактор не примет или не откатит ее.
+CREATE TABLE ai_edits (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+	instruction TEXT NOT NULL,
+	original TEXT NOT NULL,
+	revised TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'proposed',
+	created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_ai_edits_post ON ai_edits (post_id, id);
+
+-- Чат, который вводит инструкцию для AI-правки поста.
+CREATE TABLE pending_ai_edits (
+	chat_id INTEGER PRIMARY KEY,
+	post_id INTEGER NOT NULL
+);
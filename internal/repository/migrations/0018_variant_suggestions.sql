@@ -0,0 +1,4 @@
+-- Что модель предложила вместе с вариантом текста в формате json: описания
+-- картинок (JSON-массив строк) и время публикации.
+ALTER TABLE post_variants ADD COLUMN image_prompts TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_variants ADD COLUMN publish_at TEXT;
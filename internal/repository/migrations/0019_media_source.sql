@@ -0,0 +1,2 @@
+-- Поиск уже скачанного файла по адресу: кэш генератора картинок повторяет ссылки.
+CREATE INDEX idx_media_source ON media (source_url);
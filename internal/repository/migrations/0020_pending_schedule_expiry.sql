@@ -0,0 +1,3 @@
+-- Когда чат начал вводить дату: брошенное ожидание истекает и не перехватывает
+-- новые темы. У старых записей времени нет — они считаются истекшими.
+ALTER TABLE pending_schedule ADD COLUMN created_at TEXT;
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lady/internal/domain"
+)
+
+const personaColumns = `id, name, system_prompt, template, tone, length, image_style, image_count,
+	structured, created_by, created_at, updated_at`
+
+// PersonaRepository хранит персоны и их привязку к каналам.
+type PersonaRepository struct {
+	db *sql.DB
+}
+
+// NewPersonaRepository создает репозиторий персон поверх открытой базы.
+func NewPersonaRepository(db *sql.DB) *PersonaRepository {
+	return &PersonaRepository{db: db}
+}
+
+func scanPersona(row rowScanner) (domain.Persona, error) {
+	var p domain.Persona
+	var createdAt, updatedAt sql.NullString
+	if err := row.Scan(&p.ID, &p.Name, &p.SystemPrompt, &p.Template, &p.Tone, &p.Length, &p.ImageStyle, &p.ImageCount, &p.Structured, &p.CreatedBy, &createdAt, &updatedAt); err != nil {
+		return domain.Persona{}, err
+	}
+	p.CreatedAt = parseUTC(createdAt)
+	p.UpdatedAt = parseUTC(updatedAt)
+	return p, nil
+}
+
+// Create сохраняет новую персону и возвращает ее ID.
+func (r *PersonaRepository) Create(p domain.Persona) (int64, error) {
+	res, err := r.db.Exec(
+		`INSERT INTO personas (name, system_prompt, template, tone, length, image_style, image_count, structured, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.Name, p.SystemPrompt, p.Template, p.Tone, p.Length, p.ImageStyle, p.ImageCount, p.Structured, p.CreatedBy,
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения персоны %q: %v", p.Name, err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// Update сохраняет изменения персоны.
+func (r *PersonaRepository) Update(p domain.Persona) error {
+	res, err := r.db.Exec(
+		`UPDATE personas SET name = ?, system_prompt = ?, template = ?, tone = ?, length = ?,
+			image_style = ?, image_count = ?, structured = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		p.Name, p.SystemPrompt, p.Template, p.Tone, p.Length, p.ImageStyle, p.ImageCount, p.Structured, p.ID,
+	)
+	if err != nil {
+		log.Printf("Ошибка изменения персоны %d: %v", p.ID, err)
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("персона %d не найдена", p.ID)
+	}
+	return nil
+}
+
+// FindByName возвращает персону по имени.
+func (r *PersonaRepository) FindByName(name string) (domain.Persona, error) {
+	p, err := scanPersona(r.db.QueryRow(`SELECT `+personaColumns+` FROM personas WHERE name = ?`, name))
+	if err == sql.ErrNoRows {
+		return domain.Persona{}, fmt.Errorf("персона «%s» не найдена", name)
+	}
+	return p, err
+}
+
+// First возвращает самую раннюю персону — она действует, пока канал не выбрал свою.
+func (r *PersonaRepository) First() (domain.Persona, error) {
+	p, err := scanPersona(r.db.QueryRow(`SELECT ` + personaColumns + ` FROM personas ORDER BY id LIMIT 1`))
+	if err == sql.ErrNoRows {
+		return domain.Persona{}, fmt.Errorf("нет ни одной персоны, создайте ее через /persona new")
+	}
+	return p, err
+}
+
+// List возвращает все персоны.
+func (r *PersonaRepository) List() ([]domain.Persona, error) {
+	rows, err := r.db.Query(`SELECT ` + personaColumns + ` FROM personas ORDER BY id`)
+	if err != nil {
+		log.Printf("Ошибка получения персон: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var personas []domain.Persona
+	for rows.Next() {
+		p, err := scanPersona(rows)
+		if err != nil {
+			return nil, err
+		}
+		personas = append(personas, p)
+	}
+	return personas, rows.Err()
+}
+
+// ForChannel возвращает персону, выбранную для канала.
+func (r *PersonaRepository) ForChannel(channelID int64) (domain.Persona, error) {
+	p, err := scanPersona(r.db.QueryRow(
+		`SELECT `+personaColumns+` FROM personas
+		WHERE id = (SELECT persona_id FROM channel_personas WHERE channel_id = ?)`, channelID))
+	if err == sql.ErrNoRows {
+		return domain.Persona{}, fmt.Errorf("для канала %d персона не выбрана", channelID)
+	}
+	return p, err
+}
+
+// ChannelPersonas возвращает ID персоны для каждого канала, где она выбрана.
+func (r *PersonaRepository) ChannelPersonas() (map[int64]int64, error) {
+	rows, err := r.db.Query(`SELECT channel_id, persona_id FROM channel_personas`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]int64)
+	for rows.Next() {
+		var channelID, personaID int64
+		if err := rows.Scan(&channelID, &personaID); err != nil {
+			return nil, err
+		}
+		result[channelID] = personaID
+	}
+	return result, rows.Err()
+}
+
+// SetChannelPersona выбирает персону для канала.
+func (r *PersonaRepository) SetChannelPersona(channelID, personaID int64) error {
+	_, err := r.db.Exec(
+		`INSERT INTO channel_personas (channel_id, persona_id) VALUES (?, ?)
+		ON CONFLICT (channel_id) DO UPDATE SET persona_id = excluded.persona_id`,
+		channelID, personaID,
+	)
+	if err != nil {
+		log.Printf("Ошибка выбора персоны %d для канала %d: %v", personaID, channelID, err)
+	}
+	return err
+}
@@ -0,0 +1,384 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"lady/internal/domain"
+)
+
+// dbTimeLayout — формат, в котором время хранится в базе. Все метки хранятся в UTC,
+// часовой пояс редактора применяется только при вводе и показе.
+const dbTimeLayout = "2006-01-02 15:04:05"
+
+const postColumns = `id, author_id, channel_id, topic_id, status, text, variant_id, series_id,
+	publish_at, published_at, attempts, last_error, next_attempt_at, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// parseUTC разбирает метку времени из базы; пустое значение дает нулевое время.
+func parseUTC(s sql.NullString) time.Time {
+	if !s.Valid || s.String == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(dbTimeLayout, s.String)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func scanPost(row rowScanner) (domain.Post, error) {
+	var p domain.Post
+	var topicID, variantID, seriesID sql.NullInt64
+	var status string
+	var publishAtStr, publishedAtStr, nextAttemptStr, createdAtStr, updatedAtStr sql.NullString
+	if err := row.Scan(&p.ID, &p.AuthorID, &p.ChannelID, &topicID, &status, &p.Text, &variantID, &seriesID,
+		&publishAtStr, &publishedAtStr, &p.Attempts, &p.LastError, &nextAttemptStr, &createdAtStr, &updatedAtStr); err != nil {
+		return domain.Post{}, err
+	}
+	p.TopicID = topicID.Int64
+	p.VariantID = variantID.Int64
+	p.SeriesID = seriesID.Int64
+	p.Status = domain.PostStatus(status)
+	if publishAtStr.Valid && publishAtStr.String != "" {
+		publishAt, err := time.Parse(dbTimeLayout, publishAtStr.String)
+		if err != nil {
+			return domain.Post{}, fmt.Errorf("ошибка парсинга publish_at поста %d: %w", p.ID, err)
+		}
+		p.PublishAt = publishAt
+	}
+	p.NextAttemptAt = parseUTC(nextAttemptStr)
+	p.PublishedAt = parseUTC(publishedAtStr)
+	p.CreatedAt = parseUTC(createdAtStr)
+	p.UpdatedAt = parseUTC(updatedAtStr)
+	return p, nil
+}
+
+func (r *TopicRepository) queryPosts(query string, args ...interface{}) ([]domain.Post, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []domain.Post
+	for rows.Next() {
+		p, err := scanPost(rows)
+		if err != nil {
+			log.Printf("Ошибка чтения поста: %v", err)
+			continue
+		}
+		posts = append(posts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Картинки читаем после закрытия курсора: соединение с базой одно
+	rows.Close()
+	if err := r.attachImages(posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func timeValue(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil // Сохраняем NULL вместо пустой строки
+	}
+	return t.UTC().Format(dbTimeLayout)
+}
+
+func nullID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// CreatePost сохраняет новый пост с картинками и первым вариантом текста и возвращает его ID.
+func (r *TopicRepository) CreatePost(p domain.Post) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO posts (author_id, channel_id, topic_id, series_id, status, text, publish_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		p.AuthorID, p.ChannelID, nullID(p.TopicID), nullID(p.SeriesID), string(p.Status), p.Text, timeValue(p.PublishAt),
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения поста для автора %d: %v", p.AuthorID, err)
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := insertImages(tx, id, p.Images); err != nil {
+		log.Printf("Ошибка сохранения картинок поста %d: %v", id, err)
+		return 0, err
+	}
+	if _, err := addVariant(tx, id, p.Text); err != nil {
+		log.Printf("Ошибка сохранения варианта поста %d: %v", id, err)
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	log.Printf("Сохранен пост %d для автора %d", id, p.AuthorID)
+	return id, nil
+}
+
+// GetPost возвращает пост по ID.
+func (r *TopicRepository) GetPost(id int64) (domain.Post, error) {
+	p, err := scanPost(r.db.QueryRow(`SELECT `+postColumns+` FROM posts WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return domain.Post{}, fmt.Errorf("no post %d", id)
+	}
+	if err != nil {
+		log.Printf("Ошибка получения поста %d: %v", id, err)
+		return domain.Post{}, err
+	}
+	if p.Images, err = r.postImages(id); err != nil {
+		log.Printf("Ошибка получения картинок поста %d: %v", id, err)
+		return domain.Post{}, err
+	}
+	return p, nil
+}
+
+// LatestDraft возвращает последний черновик автора.
+func (r *TopicRepository) LatestDraft(authorID int64) (domain.Post, error) {
+	posts, err := r.queryPosts(
+		`SELECT `+postColumns+` FROM posts WHERE author_id = ? AND status = ? ORDER BY id DESC LIMIT 1`,
+		authorID, string(domain.PostDraft),
+	)
+	if err != nil {
+		log.Printf("Ошибка получения черновика автора %d: %v", authorID, err)
+		return domain.Post{}, err
+	}
+	if len(posts) == 0 {
+		return domain.Post{}, fmt.Errorf("no draft for author %d", authorID)
+	}
+	return posts[0], nil
+}
+
+// UpdatePostText заменяет текст поста и его выбранного варианта.
+func (r *TopicRepository) UpdatePostText(id int64, text string) error {
+	if err := r.execPost(id, `UPDATE posts SET text = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, text, id); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`UPDATE post_variants SET text = ? WHERE id = (SELECT variant_id FROM posts WHERE id = ?)`, text, id)
+	return err
+}
+
+// UpdatePostChannel меняет канал публикации поста, если канал есть в реестре привязанных.
+func (r *TopicRepository) UpdatePostChannel(id, channelID int64) error {
+	return r.execPost(id,
+		`UPDATE posts SET channel_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND EXISTS (SELECT 1 FROM channels WHERE id = ?)`,
+		channelID, id, channelID,
+	)
+}
+
+// SchedulePost ставит пост в очередь, если он все еще в статусе from.
+func (r *TopicRepository) SchedulePost(id int64, from domain.PostStatus, publishAt time.Time) error {
+	return r.execPost(id,
+		`UPDATE posts SET status = ?, publish_at = ?, attempts = 0, last_error = '', next_attempt_at = NULL,
+			updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?`,
+		string(domain.PostScheduled), timeValue(publishAt), id, string(from),
+	)
+}
+
+// UpdatePostStatus переводит пост из статуса from в статус to. Условие на from
+// защищает от гонок между планировщиком и кнопками редактора.
+func (r *TopicRepository) UpdatePostStatus(id int64, from, to domain.PostStatus) error {
+	query := `UPDATE posts SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?`
+	if to == domain.PostPublished {
+		query = `UPDATE posts SET status = ?, published_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?`
+	}
+	return r.execPost(id, query, string(to), id, string(from))
+}
+
+// RecordPublishFailure сохраняет неудачную попытку публикации: переводит пост из
+// publishing в статус to (scheduled для повтора или failed) и запоминает причину.
+func (r *TopicRepository) RecordPublishFailure(id int64, to domain.PostStatus, attempts int, lastError string, nextAttemptAt time.Time) error {
+	return r.execPost(id,
+		`UPDATE posts SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = ?`,
+		string(to), attempts, lastError, timeValue(nextAttemptAt), id, string(domain.PostPublishing),
+	)
+}
+
+// ListByStatus возвращает все посты в указанном статусе.
+func (r *TopicRepository) ListByStatus(status domain.PostStatus) ([]domain.Post, error) {
+	return r.queryPosts(`SELECT `+postColumns+` FROM posts WHERE status = ? ORDER BY id`, string(status))
+}
+
+func (r *TopicRepository) execPost(id int64, query string, args ...interface{}) error {
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		log.Printf("Ошибка изменения поста %d: %v", id, err)
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("post %d not found or changed concurrently", id)
+	}
+	return nil
+}
+
+// ListQueue возвращает запланированные посты автора в порядке публикации.
+func (r *TopicRepository) ListQueue(authorID int64) ([]domain.Post, error) {
+	posts, err := r.queryPosts(
+		`SELECT `+postColumns+` FROM posts WHERE author_id = ? AND status = ? ORDER BY publish_at, id`,
+		authorID, string(domain.PostScheduled),
+	)
+	if err != nil {
+		log.Printf("Ошибка получения очереди автора %d: %v", authorID, err)
+		return nil, err
+	}
+	return posts, nil
+}
+
+// ClaimDuePosts переводит в статус publishing посты, время публикации которых
+// наступило, и возвращает их. Захват идет в одной транзакции, поэтому пост не
+// уйдет в канал дважды.
+func (r *TopicRepository) ClaimDuePosts(now time.Time) ([]domain.Post, error) {
+	currentTime := now.UTC().Format(dbTimeLayout)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`SELECT `+postColumns+` FROM posts
+		WHERE status = ? AND publish_at <= ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+		ORDER BY publish_at, id`,
+		string(domain.PostScheduled), currentTime, currentTime,
+	)
+	if err != nil {
+		log.Printf("Ошибка запроса отложенных постов: %v", err)
+		return nil, err
+	}
+	var posts []domain.Post
+	for rows.Next() {
+		p, err := scanPost(rows)
+		if err != nil {
+			log.Printf("Ошибка чтения отложенного поста: %v", err)
+			continue
+		}
+		posts = append(posts, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range posts {
+		if _, err := tx.Exec(
+			`UPDATE posts SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			string(domain.PostPublishing), posts[i].ID,
+		); err != nil {
+			return nil, err
+		}
+		posts[i].Status = domain.PostPublishing
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if err := r.attachImages(posts); err != nil {
+		return nil, err
+	}
+	log.Printf("Найдено %d запланированных постов на %s", len(posts), currentTime)
+	return posts, nil
+}
+
+func (r *TopicRepository) SavePendingEdit(chatID, postID int64, messageID int) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO pending_edits (chat_id, post_id, message_id) VALUES (?, ?, ?)`,
+		chatID, postID, messageID,
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения редактирования для chatID %d: %v", chatID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *TopicRepository) GetPendingEdit(chatID int64) (int64, int, error) {
+	var postID int64
+	var messageID int
+	err := r.db.QueryRow(
+		`SELECT post_id, message_id FROM pending_edits WHERE chat_id = ?`,
+		chatID,
+	).Scan(&postID, &messageID)
+	if err == sql.ErrNoRows {
+		return 0, 0, fmt.Errorf("no pending edit for chatID %d", chatID)
+	}
+	if err != nil {
+		log.Printf("Ошибка получения редактирования для chatID %d: %v", chatID, err)
+		return 0, 0, err
+	}
+	return postID, messageID, nil
+}
+
+func (r *TopicRepository) ClearPendingEdit(chatID int64) error {
+	res, err := r.db.Exec(`DELETE FROM pending_edits WHERE chat_id = ?`, chatID)
+	if err != nil {
+		log.Printf("Ошибка очистки редактирования для chatID %d: %v", chatID, err)
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("no pending edit for chatID %d", chatID)
+	}
+	return nil
+}
+
+func (r *TopicRepository) SavePendingSchedule(chatID, postID int64) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO pending_schedule (chat_id, post_id, created_at) VALUES (?, ?, ?)`,
+		chatID, postID, timeValue(time.Now()),
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения ожидания даты для chatID %d: %v", chatID, err)
+		return err
+	}
+	return nil
+}
+
+// GetPendingSchedule возвращает пост, ожидающий даты, и когда ее начали ждать.
+func (r *TopicRepository) GetPendingSchedule(chatID int64) (int64, time.Time, error) {
+	var postID int64
+	var createdAt sql.NullString
+	err := r.db.QueryRow(`SELECT post_id, created_at FROM pending_schedule WHERE chat_id = ?`, chatID).Scan(&postID, &createdAt)
+	if err == sql.ErrNoRows {
+		return 0, time.Time{}, fmt.Errorf("no pending schedule for chatID %d", chatID)
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return postID, parseUTC(createdAt), nil
+}
+
+func (r *TopicRepository) ClearPendingSchedule(chatID int64) error {
+	res, err := r.db.Exec(`DELETE FROM pending_schedule WHERE chat_id = ?`, chatID)
+	if err != nil {
+		log.Printf("Ошибка очистки ожидания даты для chatID %d: %v", chatID, err)
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("no pending schedule for chatID %d", chatID)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lady/internal/domain"
+)
+
+// SeriesRepository хранит серии постов.
+type SeriesRepository struct {
+	db *sql.DB
+}
+
+// NewSeriesRepository создает репозиторий серий поверх открытой базы.
+func NewSeriesRepository(db *sql.DB) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+// seriesColumns выбирает серию вместе с числом опубликованных постов.
+const seriesColumns = `s.id, s.title, s.created_by, s.created_at,
+	(SELECT COUNT(*) FROM posts p WHERE p.series_id = s.id AND p.status = 'published')`
+
+func scanSeries(row rowScanner) (domain.Series, error) {
+	var s domain.Series
+	var createdAt sql.NullString
+	if err := row.Scan(&s.ID, &s.Title, &s.CreatedBy, &createdAt, &s.Posts); err != nil {
+		return domain.Series{}, err
+	}
+	s.CreatedAt = parseUTC(createdAt)
+	return s, nil
+}
+
+// Create сохраняет новую серию и возвращает ее ID.
+func (r *SeriesRepository) Create(s domain.Series) (int64, error) {
+	res, err := r.db.Exec(`INSERT INTO series (title, created_by) VALUES (?, ?)`, s.Title, s.CreatedBy)
+	if err != nil {
+		log.Printf("Ошибка сохранения серии %q: %v", s.Title, err)
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// Get возвращает серию по ID.
+func (r *SeriesRepository) Get(id int64) (domain.Series, error) {
+	s, err := scanSeries(r.db.QueryRow(`SELECT `+seriesColumns+` FROM series s WHERE s.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return domain.Series{}, fmt.Errorf("серия %d не найдена", id)
+	}
+	return s, err
+}
+
+// List возвращает все серии, новые первыми.
+func (r *SeriesRepository) List() ([]domain.Series, error) {
+	rows, err := r.db.Query(`SELECT ` + seriesColumns + ` FROM series s ORDER BY s.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []domain.Series
+	for rows.Next() {
+		s, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// PublishedTexts возвращает тексты последних limit опубликованных постов серии
+// в порядке публикации.
+func (r *SeriesRepository) PublishedTexts(seriesID int64, limit int) ([]string, error) {
+	rows, err := r.db.Query(
+		`SELECT text FROM (
+			SELECT text, published_at, id FROM posts WHERE series_id = ? AND status = ?
+			ORDER BY published_at DESC, id DESC LIMIT ?
+		) ORDER BY published_at, id`,
+		seriesID, string(domain.PostPublished), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+	return texts, rows.Err()
+}
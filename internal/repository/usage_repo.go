@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"lady/internal/domain"
+)
+
+// UsageRepository хранит расход на генерацию.
+type UsageRepository struct {
+	db *sql.DB
+}
+
+// NewUsageRepository создает репозиторий расхода поверх открытой базы.
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+const usageTotalsColumns = `COALESCE(SUM(prompt_tokens + completion_tokens), 0), COALESCE(SUM(images), 0),
+	COALESCE(SUM(cost), 0), COUNT(*)`
+
+func scanTotals(row rowScanner) (domain.UsageTotals, error) {
+	var t domain.UsageTotals
+	err := row.Scan(&t.Tokens, &t.Images, &t.Cost, &t.Requests)
+	return t, err
+}
+
+// Record сохраняет расход одного запроса.
+func (r *UsageRepository) Record(u domain.Usage) error {
+	_, err := r.db.Exec(
+		`INSERT INTO usage (user_id, channel_id, kind, prompt_tokens, completion_tokens, images, cost)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		u.UserID, u.ChannelID, string(u.Kind), u.PromptTokens, u.CompletionTokens, u.Images, u.Cost,
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения расхода редактора %d: %v", u.UserID, err)
+	}
+	return err
+}
+
+// UserTotals возвращает расход редактора начиная с since.
+func (r *UsageRepository) UserTotals(userID int64, since time.Time) (domain.UsageTotals, error) {
+	return scanTotals(r.db.QueryRow(
+		`SELECT `+usageTotalsColumns+` FROM usage WHERE user_id = ? AND created_at >= ?`,
+		userID, since.UTC().Format(dbTimeLayout),
+	))
+}
+
+// Totals возвращает общий расход начиная с since.
+func (r *UsageRepository) Totals(since time.Time) (domain.UsageTotals, error) {
+	return scanTotals(r.db.QueryRow(
+		`SELECT `+usageTotalsColumns+` FROM usage WHERE created_at >= ?`, since.UTC().Format(dbTimeLayout),
+	))
+}
+
+// ChannelTotals возвращает расход по каналам начиная с since.
+func (r *UsageRepository) ChannelTotals(since time.Time) (map[int64]domain.UsageTotals, error) {
+	rows, err := r.db.Query(
+		`SELECT channel_id, `+usageTotalsColumns+` FROM usage WHERE created_at >= ? GROUP BY channel_id`,
+		since.UTC().Format(dbTimeLayout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]domain.UsageTotals)
+	for rows.Next() {
+		var channelID int64
+		var t domain.UsageTotals
+		if err := rows.Scan(&channelID, &t.Tokens, &t.Images, &t.Cost, &t.Requests); err != nil {
+			return nil, err
+		}
+		result[channelID] = t
+	}
+	return result, rows.Err()
+}
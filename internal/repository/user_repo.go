@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+
+	"lady/internal/domain"
+)
+
+// UserRepository хранит настройки редакторов.
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository создает репозиторий настроек поверх открытой базы.
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Get возвращает настройки редактора; для нового редактора — пустые.
+func (r *UserRepository) Get(id int64) (domain.User, error) {
+	u := domain.User{ID: id}
+	var defaultChannel, series sql.NullInt64
+	err := r.db.QueryRow(`SELECT default_channel_id, timezone, series_id FROM users WHERE id = ?`, id).Scan(&defaultChannel, &u.TimeZone, &series)
+	if err == sql.ErrNoRows {
+		return u, nil
+	}
+	if err != nil {
+		log.Printf("Ошибка получения настроек пользователя %d: %v", id, err)
+		return u, err
+	}
+	u.DefaultChannelID = defaultChannel.Int64
+	u.SeriesID = series.Int64
+	return u, nil
+}
+
+// SetDefaultChannel запоминает канал по умолчанию для редактора.
+func (r *UserRepository) SetDefaultChannel(userID, channelID int64) error {
+	_, err := r.db.Exec(
+		`INSERT INTO users (id, default_channel_id) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET default_channel_id = excluded.default_channel_id`,
+		userID, nullID(channelID),
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения канала по умолчанию для %d: %v", userID, err)
+		return err
+	}
+	return nil
+}
+
+// SetTimeZone запоминает часовой пояс редактора.
+func (r *UserRepository) SetTimeZone(userID int64, timeZone string) error {
+	_, err := r.db.Exec(
+		`INSERT INTO users (id, timezone) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET timezone = excluded.timezone`,
+		userID, timeZone,
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения часового пояса для %d: %v", userID, err)
+		return err
+	}
+	return nil
+}
+
+// SetSeries запоминает серию, в которой пишет редактор; 0 — вне серий.
+func (r *UserRepository) SetSeries(userID, seriesID int64) error {
+	_, err := r.db.Exec(
+		`INSERT INTO users (id, series_id) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET series_id = excluded.series_id`,
+		userID, nullID(seriesID),
+	)
+	if err != nil {
+		log.Printf("Ошибка сохранения серии для %d: %v", userID, err)
+		return err
+	}
+	return nil
+}
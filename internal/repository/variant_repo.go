@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"lady/internal/domain"
+)
+
+// addVariant сохраняет вариант текста и делает его выбранным.
+func addVariant(tx *sql.Tx, postID int64, text string) (int64, error) {
+	res, err := tx.Exec(`INSERT INTO post_variants (post_id, text) VALUES (?, ?)`, postID, text)
+	if err != nil {
+		return 0, err
+	}
+	variantID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(`UPDATE posts SET text = ?, variant_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, text, variantID, postID)
+	return variantID, err
+}
+
+// AddVariant сохраняет новый вариант текста поста и делает его выбранным.
+func (r *TopicRepository) AddVariant(postID int64, text string) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	variantID, err := addVariant(tx, postID, text)
+	if err != nil {
+		log.Printf("Ошибка сохранения варианта поста %d: %v", postID, err)
+		return 0, err
+	}
+	return variantID, tx.Commit()
+}
+
+// SelectVariant делает вариант выбранным и копирует его текст в пост.
+func (r *TopicRepository) SelectVariant(postID, variantID int64) error {
+	res, err := r.db.Exec(
+		`UPDATE posts SET variant_id = v.id, text = v.text, updated_at = CURRENT_TIMESTAMP
+		FROM post_variants v WHERE v.id = ? AND v.post_id = posts.id AND posts.id = ?`,
+		variantID, postID,
+	)
+	if err != nil {
+		log.Printf("Ошибка выбора варианта %d поста %d: %v", variantID, postID, err)
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("вариант %d не относится к посту %d", variantID, postID)
+	}
+	return nil
+}
+
+// SetVariantSuggestions запоминает описания картинок и время публикации,
+// которые модель предложила вместе с вариантом.
+func (r *TopicRepository) SetVariantSuggestions(variantID int64, prompts []string, publishAt time.Time) error {
+	var encoded string
+	if len(prompts) > 0 {
+		data, err := json.Marshal(prompts)
+		if err != nil {
+			return err
+		}
+		encoded = string(data)
+	}
+	_, err := r.db.Exec(`UPDATE post_variants SET image_prompts = ?, publish_at = ? WHERE id = ?`, encoded, timeValue(publishAt), variantID)
+	if err != nil {
+		log.Printf("Ошибка сохранения предложений варианта %d: %v", variantID, err)
+	}
+	return err
+}
+
+// ListVariants возвращает варианты текста поста в порядке создания.
+func (r *TopicRepository) ListVariants(postID int64) ([]domain.PostVariant, error) {
+	rows, err := r.db.Query(
+		`SELECT id, post_id, text, image_prompts, publish_at, created_at FROM post_variants WHERE post_id = ? ORDER BY id`, postID,
+	)
+	if err != nil {
+		log.Printf("Ошибка получения вариантов поста %d: %v", postID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []domain.PostVariant
+	for rows.Next() {
+		var v domain.PostVariant
+		var prompts string
+		var publishAt, createdAt sql.NullString
+		if err := rows.Scan(&v.ID, &v.PostID, &v.Text, &prompts, &publishAt, &createdAt); err != nil {
+			return nil, err
+		}
+		if prompts != "" {
+			if err := json.Unmarshal([]byte(prompts), &v.ImagePrompts); err != nil {
+				log.Printf("Ошибка разбора описаний картинок варианта %d: %v", v.ID, err)
+			}
+		}
+		v.PublishAt = parseUTC(publishAt)
+		v.CreatedAt = parseUTC(createdAt)
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
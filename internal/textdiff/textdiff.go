@@ -0,0 +1,139 @@
+// Package textdiff сравнивает два текста по словам.
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kind — что случилось с фрагментом текста.
+type Kind int
+
+const (
+	Equal  Kind = iota // фрагмент есть в обоих текстах
+	Delete             // фрагмент есть только в старом тексте
+	Insert             // фрагмент есть только в новом тексте
+)
+
+// Op — фрагмент сравнения: слова вместе с пробелами между ними.
+type Op struct {
+	Kind Kind
+	Text string
+}
+
+// maxCells ограничивает таблицу сравнения: для очень длинных текстов diff
+// вырождается в «удалить всё, вставить всё».
+const maxCells = 4_000_000
+
+// Words сравнивает тексты по словам и возвращает фрагменты по порядку.
+// Соседние фрагменты одного вида склеены; пробелы между измененными словами
+// относятся к изменению.
+func Words(before, after string) []Op {
+	a, b := tokens(before), tokens(after)
+	if len(a)*len(b) > maxCells {
+		return merge([]Op{{Delete, before}, {Insert, after}})
+	}
+
+	// lcs[i][j] — длина общей подпоследовательности a[i:] и b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []Op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, Op{Equal, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Op{Delete, a[i]})
+			i++
+		default:
+			ops = append(ops, Op{Insert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, Op{Delete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, Op{Insert, b[j]})
+	}
+	return merge(absorbSpaces(ops))
+}
+
+// tokens делит текст на слова и пробельные промежутки, не теряя ни символа.
+func tokens(s string) []string {
+	var res []string
+	start, space := 0, false
+	for i, r := range s {
+		if i > start && unicode.IsSpace(r) != space {
+			res = append(res, s[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+	if start < len(s) {
+		res = append(res, s[start:])
+	}
+	return res
+}
+
+// absorbSpaces переносит общий пробел, зажатый между двумя изменениями, в
+// изменения: «[-старое-] [+новое+]» читается лучше, чем два разрыва.
+func absorbSpaces(ops []Op) []Op {
+	for i := 1; i+1 < len(ops); i++ {
+		if ops[i].Kind == Equal && strings.TrimSpace(ops[i].Text) == "" &&
+			ops[i-1].Kind != Equal && ops[i+1].Kind != Equal {
+			space := ops[i].Text
+			ops[i] = Op{Delete, space}
+			ops = append(ops[:i+1], append([]Op{{Insert, space}}, ops[i+1:]...)...)
+			i++
+		}
+	}
+	return ops
+}
+
+// merge склеивает соседние фрагменты одного вида. Удаления внутри изменения
+// идут раньше вставок.
+func merge(ops []Op) []Op {
+	var res []Op
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			if n := len(res); n > 0 && res[n-1].Kind == Equal {
+				res[n-1].Text += ops[i].Text
+			} else {
+				res = append(res, ops[i])
+			}
+			i++
+			continue
+		}
+		var deleted, inserted strings.Builder
+		for ; i < len(ops) && ops[i].Kind != Equal; i++ {
+			if ops[i].Kind == Delete {
+				deleted.WriteString(ops[i].Text)
+			} else {
+				inserted.WriteString(ops[i].Text)
+			}
+		}
+		if deleted.Len() > 0 {
+			res = append(res, Op{Delete, deleted.String()})
+		}
+		if inserted.Len() > 0 {
+			res = append(res, Op{Insert, inserted.String()})
+		}
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          []Op
+	}{
+		{"equal", "один два", "один два", []Op{{Equal, "один два"}}},
+		{"empty before", "", "новый текст", []Op{{Insert, "новый текст"}}},
+		{"empty after", "старый текст", "", []Op{{Delete, "старый текст"}}},
+		{"replace word", "кошки спят днем", "кошки спят ночью",
+			[]Op{{Equal, "кошки спят "}, {Delete, "днем"}, {Insert, "ночью"}}},
+		{"insert word", "кошки спят", "кошки много спят",
+			[]Op{{Equal, "кошки "}, {Insert, "много "}, {Equal, "спят"}}},
+		{"delete word", "кошки очень много спят", "кошки много спят",
+			[]Op{{Equal, "кошки "}, {Delete, "очень "}, {Equal, "много спят"}}},
+		{"space between changes", "а б в", "а x y",
+			[]Op{{Equal, "а "}, {Delete, "б в"}, {Insert, "x y"}}},
+		{"newlines kept", "абзац\n\nвторой", "абзац\n\nтретий",
+			[]Op{{Equal, "абзац\n\n"}, {Delete, "второй"}, {Insert, "третий"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Words(tt.before, tt.after)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q, %q) = %+v, want %+v", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWordsRestores проверяет, что из фрагментов собираются оба текста.
+func TestWordsRestores(t *testing.T) {
+	pairs := [][2]string{
+		{"Сегодня расскажу про кошек. Они спят по 16 часов!", "Сегодня расскажу про котов: они спят по 16 часов в сутки."},
+		{"  пробелы  в начале", "пробелы в конце  "},
+		{"😀 эмодзи", "эмодзи 😀"},
+	}
+	for _, p := range pairs {
+		var before, after strings.Builder
+		for _, op := range Words(p[0], p[1]) {
+			if op.Kind != Insert {
+				before.WriteString(op.Text)
+			}
+			if op.Kind != Delete {
+				after.WriteString(op.Text)
+			}
+		}
+		if before.String() != p[0] || after.String() != p[1] {
+			t.Errorf("Words(%q, %q) restores %q, %q", p[0], p[1], before.String(), after.String())
+		}
+	}
+}
+
+func TestWordsTooLong(t *testing.T) {
+	before := strings.Repeat("а ", 3000)
+	after := strings.Repeat("б ", 3000)
+	got := Words(before, after)
+	want := []Op{{Delete, before}, {Insert, after}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Words on long texts returned %d ops, want delete and insert", len(got))
+	}
+}
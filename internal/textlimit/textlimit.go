@@ -0,0 +1,105 @@
+// Package textlimit считает длину текста так же, как Telegram, и режет текст
+// по границам слов.
+package textlimit
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	// Caption — предел подписи к фото и медиа-группе.
+	Caption = 1024
+	// Message — предел обычного сообщения.
+	Message = 4096
+	// MinLimit — самый маленький предел для Cut и Split: в него помещается любой
+	// символ, даже эмодзи из двух единиц UTF-16.
+	MinLimit = 2
+)
+
+// Len возвращает длину текста в единицах UTF-16: так Telegram проверяет пределы.
+// Кириллица занимает одну единицу, многие эмодзи — две.
+func Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if w := utf16.RuneLen(r); w > 0 {
+			n += w
+		} else {
+			n++ // некорректный UTF-8 Telegram заменит одним символом
+		}
+	}
+	return n
+}
+
+// Cut делит текст на начало длиной не больше limit и остаток. Граница ищется по
+// абзацу, затем по концу предложения, затем по пробелу — слово не режется, если
+// в пределе есть хоть один пробел. Предел меньше MinLimit — ошибка вызывающего:
+// Cut паникует, а не возвращает начало длиннее предела.
+func Cut(text string, limit int) (string, string) {
+	if limit < MinLimit {
+		panic(fmt.Sprintf("textlimit: предел %d меньше %d", limit, MinLimit))
+	}
+	if Len(text) <= limit {
+		return text, ""
+	}
+
+	end, n := len(text), 0
+	for i, r := range text {
+		w := utf16.RuneLen(r)
+		if w < 0 {
+			w = 1
+		}
+		if n+w > limit {
+			end = i
+			break
+		}
+		n += w
+	}
+	// При пределе от MinLimit в окно попадает хотя бы один символ, и Split не зациклится
+	cut := lastBreak(text[:end])
+	head := strings.TrimRightFunc(text[:cut], unicode.IsSpace)
+	rest := strings.TrimLeftFunc(text[cut:], unicode.IsSpace)
+	return head, rest
+}
+
+// Split режет текст на части длиной не больше limit. Как и Cut, паникует при
+// пределе меньше MinLimit.
+func Split(text string, limit int) []string {
+	var parts []string
+	for text != "" {
+		head, rest := Cut(text, limit)
+		if head != "" {
+			parts = append(parts, head)
+		}
+		text = rest
+	}
+	return parts
+}
+
+// lastBreak возвращает байтовую позицию, по которой лучше всего резать окно.
+// Абзац и предложение подходят, только если начало получится не короче половины окна.
+func lastBreak(window string) int {
+	half := len(window) / 2
+	if i := strings.LastIndex(window, "\n\n"); i >= half {
+		return i
+	}
+
+	sentence := -1
+	var prev rune
+	for i, r := range window {
+		if unicode.IsSpace(r) && strings.ContainsRune(".!?…", prev) {
+			sentence = i
+		}
+		prev = r
+	}
+	if sentence >= half {
+		return sentence
+	}
+
+	if i := strings.LastIndexFunc(window, unicode.IsSpace); i > 0 {
+		return i
+	}
+	return len(window)
+}
@@ -0,0 +1,75 @@
+package textlimit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLen(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"привет", 6},
+		{"😀", 2},
+		{"ок 👍🏻", 7},
+		{"\xff", 1},
+	}
+	for _, tt := range tests {
+		if got := Len(tt.text); got != tt.want {
+			t.Errorf("Len(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCut(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		limit      int
+		head, rest string
+	}{
+		{"fits", "короткий текст", 100, "короткий текст", ""},
+		{"space", "один два три", 8, "один", "два три"},
+		{"sentence", "Первое предложение. Второе длинное", 28, "Первое предложение.", "Второе длинное"},
+		{"paragraph", "Первый абзац.\n\nВторой абзац текста", 24, "Первый абзац.", "Второй абзац текста"},
+		{"no space", "слово", 3, "сло", "во"},
+		{"emoji not split", "😀😀", 3, "😀", "😀"},
+		{"emoji at min limit", "😀😀", MinLimit, "😀", "😀"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, rest := Cut(tt.text, tt.limit)
+			if head != tt.head || rest != tt.rest {
+				t.Errorf("Cut(%q, %d) = %q, %q; want %q, %q", tt.text, tt.limit, head, rest, tt.head, tt.rest)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	text := strings.Repeat("слово ", 1000)
+	parts := Split(text, Caption)
+	if len(parts) < 2 {
+		t.Fatalf("Split returned %d parts, want several", len(parts))
+	}
+	for i, p := range parts {
+		if Len(p) > Caption {
+			t.Errorf("part %d is %d units long, limit %d", i, Len(p), Caption)
+		}
+	}
+	if got := strings.TrimSpace(strings.Join(parts, " ")); got != strings.TrimSpace(text) {
+		t.Errorf("joined parts lost text: %d != %d bytes", len(got), len(strings.TrimSpace(text)))
+	}
+}
+
+func TestCutRejectsTinyLimit(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Cut with a limit below MinLimit did not panic")
+		}
+	}()
+	Cut("😀", MinLimit-1)
+}
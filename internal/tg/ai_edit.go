@@ -0,0 +1,164 @@
+package tg
+
+import (
+	"fmt"
+	"html"
+	"lady/internal/domain"
+	"lady/internal/textdiff"
+	"lady/internal/textlimit"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// aiEditHint — подсказка с примерами инструкций для AI-правки.
+const aiEditHint = "Например: «сделай короче», «убери эмодзи», «добавь призыв подписаться»."
+
+// aiEditKeyboard возвращает кнопки предложенной правки.
+func aiEditKeyboard(e domain.AIEdit) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Принять", fmt.Sprintf("ai_ok:%d:%d", e.PostID, e.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Оставить как было", fmt.Sprintf("ai_undo:%d:%d", e.PostID, e.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🪄 Другая правка", fmt.Sprintf("ai_edit:%d", e.PostID)),
+		),
+	)
+}
+
+// handleAIEditCallback обрабатывает кнопки AI-правки: запрос инструкции,
+// принятие правки и откат.
+func (h *Handler) handleAIEditCallback(update tgbotapi.Update, post domain.Post, action, arg string) {
+	callbackID := update.CallbackQuery.ID
+	chatID := update.CallbackQuery.Message.Chat.ID
+	messageID := update.CallbackQuery.Message.MessageID
+
+	if action == "ai_edit" {
+		h.api.Request(tgbotapi.NewCallback(callbackID, "AI-правка"))
+		if !post.Editable() {
+			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Пост уже %s, текст менять поздно.", post.Status.Title())))
+			return
+		}
+		h.usecase.ClearPendingEdit(chatID)
+		if err := h.usecase.SavePendingAIEdit(chatID, post.ID); err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при сохранении данных для редактирования"))
+			return
+		}
+		h.api.Send(tgbotapi.NewMessage(chatID, "Напишите, что изменить в посте, — модель перепишет текст и покажет разницу.\n"+aiEditHint))
+		return
+	}
+
+	editID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Правка не найдена"))
+		return
+	}
+	e, err := h.usecase.GetAIEdit(post.ID, editID)
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(callbackID, err.Error()))
+		return
+	}
+
+	switch action {
+	case "ai_ok":
+		post, err := h.usecase.AcceptAIEdit(post, e.ID)
+		if err != nil {
+			h.api.Request(tgbotapi.NewCallback(callbackID, err.Error()))
+			return
+		}
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Правка принята"))
+		// Принятую правку можно откатить той же кнопкой
+		h.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Вернуть как было", fmt.Sprintf("ai_undo:%d:%d", post.ID, e.ID)),
+		))))
+		h.sendPost(chatID, post)
+		h.warnLongText(chatID, post.ID, post.Text)
+
+	case "ai_undo":
+		post, err := h.usecase.RevertAIEdit(post, e.ID)
+		if err != nil {
+			h.api.Request(tgbotapi.NewCallback(callbackID, err.Error()))
+			return
+		}
+		h.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+		if e.Status == domain.AIEditProposed {
+			h.api.Request(tgbotapi.NewCallback(callbackID, "Текст не изменился"))
+			return
+		}
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Прежний текст возвращен"))
+		h.sendPost(chatID, post)
+	}
+}
+
+// applyAIEdit переписывает пост по инструкции редактора и показывает, что
+// изменилось. Текст поста меняется, только когда редактор примет правку.
+func (h *Handler) applyAIEdit(chatID, postID int64, instruction string) {
+	post, err := h.usecase.GetPost(chatID, postID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+	channelID := post.ChannelID
+	if channelID == 0 {
+		channelID, _ = h.channelUsecase.DefaultChannel(chatID)
+	}
+	persona, err := h.personaUsecase.ForChannel(channelID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Не получилось править: %v", err)))
+		return
+	}
+
+	h.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+	// Повтор той же инструкции — просьба о другом варианте, а не о том же ответе
+	revised, err := h.generateUsecase.For(chatID, channelID).Fresh().Rewrite(persona, post.Text, instruction)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Не получилось править: %v", err)))
+		return
+	}
+	e, err := h.usecase.ProposeAIEdit(post, instruction, revised)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Правка не сохранена: %v", err)))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, renderAIEdit(e))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = aiEditKeyboard(e)
+	if _, err := h.api.Send(msg); err != nil {
+		log.Printf("Ошибка отправки AI-правки поста %d: %v", post.ID, err)
+	}
+}
+
+// renderAIEdit показывает правку в HTML: удаленное зачеркнуто, добавленное
+// выделено жирным. Если сравнение не помещается в сообщение, показывается
+// только новый текст.
+func renderAIEdit(e domain.AIEdit) string {
+	header := fmt.Sprintf("🪄 Правка поста #%d: «%s»\n\n", e.PostID, e.Instruction)
+	footer := "\n\nЗачеркнуто — удалено, жирным — добавлено."
+
+	ops := textdiff.Words(e.Original, e.Revised)
+	var visible int
+	var body strings.Builder
+	for _, op := range ops {
+		visible += textlimit.Len(op.Text)
+		text := html.EscapeString(op.Text)
+		switch op.Kind {
+		case textdiff.Delete:
+			body.WriteString("<s>" + text + "</s>")
+		case textdiff.Insert:
+			body.WriteString("<b>" + text + "</b>")
+		default:
+			body.WriteString(text)
+		}
+	}
+	if textlimit.Len(header)+visible+textlimit.Len(footer) <= textlimit.Message {
+		return html.EscapeString(header) + body.String() + footer
+	}
+
+	footer = "\n\nСравнение не помещается в сообщение, это новый текст целиком."
+	revised, _ := textlimit.Cut(e.Revised, textlimit.Message-textlimit.Len(header)-textlimit.Len(footer))
+	return html.EscapeString(header) + html.EscapeString(revised) + footer
+}
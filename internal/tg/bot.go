@@ -2,9 +2,10 @@ package tg
 
 import (
 	"fmt"
+	"lady/internal/domain"
 	"lady/internal/usecase"
 	"log"
-	"strconv"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -18,7 +19,7 @@ type Bot struct {
 }
 
 // NewBot создает новый экземпляр бота.
-func NewBot(token string, uc *usecase.TopicUsecase, tuc *usecase.GenerateUsecase, apiKey string) *Bot {
+func NewBot(token string, uc *usecase.TopicUsecase, tuc *usecase.GenerateUsecase, cuc *usecase.ChannelUsecase, uuc *usecase.UserUsecase, puc *usecase.PersonaUsecase, muc *usecase.MediaUsecase, usc *usecase.UsageUsecase, moc *usecase.ModerationUsecase, suc *usecase.SeriesUsecase) *Bot {
 	bot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		log.Fatalf("Failed to create bot: %v", err)
@@ -27,12 +28,23 @@ func NewBot(token string, uc *usecase.TopicUsecase, tuc *usecase.GenerateUsecase
 	if err != nil {
 		log.Fatalf("Failed to delete webhook: %v", err)
 	}
-	handler := NewHandler(bot, uc, tuc, apiKey)
+	handler := NewHandler(bot, uc, tuc, cuc, uuc, puc, muc, usc, moc, suc)
+	usc.SetNotifier(func(chatID int64, text string) {
+		if _, err := bot.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+			log.Printf("Ошибка отправки предупреждения о бюджете: %v", err)
+		}
+	})
 	return &Bot{api: bot, handler: handler, usecase: uc}
 }
 
 // Start запускает бота и фоновую задачу для отложенных постов.
 func (b *Bot) Start() {
+	// Посты, публикация которых оборвалась при остановке бота, отдаем на решение авторам
+	for _, post := range b.usecase.FailInterruptedPosts() {
+		log.Printf("Публикация поста %d была прервана остановкой бота", post.ID)
+		b.handler.sendFailure(post.AuthorID, post.ID, fmt.Errorf("публикация прервана перезапуском бота, проверьте канал"))
+	}
+
 	// Запускаем фоновую задачу для проверки отложенных постов
 	go b.runScheduledPosts()
 
@@ -40,78 +52,133 @@ func (b *Bot) Start() {
 	u.Timeout = 60
 	updates := b.api.GetUpdatesChan(u)
 
+	// Обновления одного чата обрабатываются по порядку, разные чаты — параллельно:
+	// долгая генерация у одного редактора не задерживает остальных
+	queues := newChatQueues(b.handle)
 	for update := range updates {
-		if update.Message != nil {
-			if update.Message.IsCommand() {
-				b.handler.HandleCommand(update)
-				continue
-			}
-			if update.Message.Document != nil {
-				b.handler.HandleFile(update)
-				continue
-			}
-			if update.Message.Text != "" {
-				b.handler.HandleText(update)
-			}
-		} else if update.CallbackQuery != nil {
-			b.handler.HandleCallback(update)
+		queues.push(updateChatID(update), update)
+	}
+}
+
+// chatQueues раздает обновления по чатам. У чата с очередью есть ровно один
+// обработчик; он завершается, как только очередь пустеет, и удаляет ее, так что
+// простаивающие чаты не держат ни горутин, ни памяти.
+type chatQueues struct {
+	handle func(tgbotapi.Update)
+
+	mu     sync.Mutex
+	queues map[int64][]tgbotapi.Update
+}
+
+func newChatQueues(handle func(tgbotapi.Update)) *chatQueues {
+	return &chatQueues{handle: handle, queues: make(map[int64][]tgbotapi.Update)}
+}
+
+// push ставит обновление в очередь чата, не дожидаясь обработки предыдущих.
+func (q *chatQueues) push(chatID int64, update tgbotapi.Update) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pending, busy := q.queues[chatID]
+	q.queues[chatID] = append(pending, update)
+	if !busy {
+		go q.serve(chatID)
+	}
+}
+
+// serve обрабатывает очередь чата, пока в ней есть обновления.
+func (q *chatQueues) serve(chatID int64) {
+	for {
+		q.mu.Lock()
+		pending := q.queues[chatID]
+		if len(pending) == 0 {
+			delete(q.queues, chatID)
+			q.mu.Unlock()
+			return
 		}
+		update := pending[0]
+		q.queues[chatID] = pending[1:]
+		q.mu.Unlock()
+
+		q.handle(update)
+	}
+}
+
+// updateChatID возвращает чат, к которому относится обновление.
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
+
+// handle передает обновление обработчику по его виду.
+func (b *Bot) handle(update tgbotapi.Update) {
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		b.handler.HandleCommand(update)
+	case update.Message != nil && update.Message.Document != nil:
+		b.handler.HandleFile(update)
+	case update.Message != nil && len(update.Message.Photo) > 0:
+		b.handler.HandlePhoto(update)
+	case update.Message != nil && update.Message.Text != "":
+		b.handler.HandleText(update)
+	case update.CallbackQuery != nil:
+		b.handler.HandleCallback(update)
 	}
 }
 
 // runScheduledPosts проверяет и публикует отложенные посты с фотографиями.
 func (b *Bot) runScheduledPosts() {
-	const channelID = "-1002848619245"
-	// Convert channelID to int64
-	channelIDInt, err := strconv.ParseInt(channelID, 10, 64)
-	if err != nil {
-		log.Fatalf("Failed to parse channelID %s to int64: %v", channelID, err)
-	}
 	ticker := time.NewTicker(10 * time.Second)
 	for range ticker.C {
 		log.Printf("Проверка отложенных постов на %s", time.Now().Format("02.01.2006 15:04:05"))
-		posts := b.usecase.GetScheduledPosts()
+		posts := b.usecase.ClaimDuePosts()
 		if len(posts) == 0 {
 			log.Printf("Нет постов для публикации")
 			continue
 		}
-		for chatID, post := range posts {
-			log.Printf("Обработка поста для chatID %d, запланированного на %s", chatID, post.PublishAt.Format("02.01.2006 15:04:05"))
-			if post.Img1 == "" || post.Img2 == "" {
-				log.Printf("Ошибка: изображения отсутствуют для chatID %d", chatID)
-				continue
-			}
-			// Truncate caption to 1024 characters
-			caption := post.Text
-			if len(caption) > 1024 {
-				caption = caption[:1024]
-				log.Printf("Текст поста для chatID %d укорочен до 1024 символов", chatID)
-			}
-			log.Printf("Длина подписи для chatID %d: %d символов", chatID, len(caption))
-			// Создаем медиа-группу для текста и фотографий
-			mediaGroup := tgbotapi.NewMediaGroup(channelIDInt, []interface{}{
-				tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(post.Img1)),
-				tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(post.Img2)),
-			})
-			// Correct type assertion
-			media := mediaGroup.Media[0].(tgbotapi.InputMediaPhoto)
-			media.Caption = caption
-			mediaGroup.Media[0] = media
-			if _, err := b.api.Send(mediaGroup); err != nil {
-				log.Printf("Ошибка публикации медиа-группы для chatID %d: %v", chatID, err)
+		for _, post := range posts {
+			log.Printf("Обработка поста %d автора %d, запланированного на %s", post.ID, post.AuthorID, post.PublishAt.Format("02.01.2006 15:04:05"))
+			// Пост, на котором сработала модерация, ждет решения редактора и не повторяется
+			if verdict := b.handler.moderationUsecase.Check(post); verdict.Action != domain.ModerationAllow {
+				log.Printf("Пост %d не прошел модерацию: %s", post.ID, verdict.Summary())
+				if err := b.usecase.MarkFailed(post.ID, fmt.Errorf("модерация: %s", verdict.Summary())); err != nil {
+					log.Printf("Ошибка смены статуса поста %d: %v", post.ID, err)
+				}
+				b.handler.sendModeration(post.AuthorID, post, verdict)
 				continue
 			}
-			if err := b.usecase.ClearPendingPost(chatID); err != nil {
-				log.Printf("Ошибка очистки поста для chatID %d: %v", chatID, err)
-			} else {
-				log.Printf("Пост для chatID %d успешно опубликован и очищен", chatID)
-				notifyMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Ваш пост с фотографиями опубликован в канале на %s", time.Now().Format("02.01.2006 15:04")))
-				if len(post.Text) > 1024 {
-					notifyMsg.Text += "\nВнимание: текст поста был укорочен из-за ограничений Telegram."
+			split, err := b.handler.publishToChannel(post)
+			if err != nil {
+				log.Printf("Ошибка публикации поста %d автора %d: %v", post.ID, post.AuthorID, err)
+				failure, ferr := b.usecase.RecordPublishFailure(post.ID, err)
+				if ferr != nil {
+					log.Printf("Ошибка смены статуса поста %d: %v", post.ID, ferr)
+					continue
 				}
-				if _, err := b.api.Send(notifyMsg); err != nil {
-					log.Printf("Ошибка отправки уведомления пользователю chatID %d: %v", chatID, err)
+				if failure.Final {
+					log.Printf("Пост %d не опубликован после %d попыток", post.ID, failure.Attempts)
+					b.handler.sendFailure(post.AuthorID, post.ID, fmt.Errorf("%v (попыток: %d)", err, failure.Attempts))
+				} else {
+					log.Printf("Повтор публикации поста %d в %s (попытка %d)", post.ID, failure.NextAttemptAt.Format("02.01.2006 15:04:05"), failure.Attempts+1)
 				}
+				continue
+			}
+			if err := b.usecase.MarkPublished(post.ID); err != nil {
+				log.Printf("Ошибка смены статуса поста %d: %v", post.ID, err)
+			}
+			log.Printf("Пост %d автора %d успешно опубликован", post.ID, post.AuthorID)
+			notifyMsg := tgbotapi.NewMessage(post.AuthorID, fmt.Sprintf("Ваш пост с фотографиями опубликован в канале на %s", time.Now().In(b.handler.userUsecase.Location(post.AuthorID)).Format("02.01.2006 15:04")))
+			if split {
+				notifyMsg.Text += "\nТекст не поместился в подпись — продолжение опубликовано ответом."
+			}
+			if _, err := b.api.Send(notifyMsg); err != nil {
+				log.Printf("Ошибка отправки уведомления пользователю chatID %d: %v", post.AuthorID, err)
 			}
 		}
 	}
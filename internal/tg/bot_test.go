@@ -0,0 +1,79 @@
+package tg
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestChatQueues(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	seen := make(map[int64][]int)
+	var wg sync.WaitGroup
+
+	q := newChatQueues(func(update tgbotapi.Update) {
+		defer wg.Done()
+		chatID := update.Message.Chat.ID
+		if chatID == 1 && update.UpdateID == 0 {
+			<-release // долгая генерация в первом чате
+		}
+		mu.Lock()
+		seen[chatID] = append(seen[chatID], update.UpdateID)
+		mu.Unlock()
+	})
+	push := func(chatID int64, id int) {
+		wg.Add(1)
+		q.push(chatID, tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}}})
+	}
+
+	for i := 0; i < 100; i++ {
+		push(1, i)
+	}
+	push(2, 0)
+
+	// Второй чат обрабатывается, пока первый занят
+	deadline := time.After(time.Second)
+	for {
+		mu.Lock()
+		done := len(seen[2]) == 1
+		mu.Unlock()
+		if done {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("chat 2 is blocked behind chat 1")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	close(release)
+	wg.Wait()
+	for i, id := range seen[1] {
+		if id != i {
+			t.Fatalf("chat 1 handled updates out of order: %v", seen[1])
+		}
+	}
+	if len(seen[1]) != 100 {
+		t.Fatalf("chat 1 handled %d updates, want 100", len(seen[1]))
+	}
+
+	// Очереди удаляются вместе с обработчиками
+	deadline = time.After(time.Second)
+	for {
+		q.mu.Lock()
+		left := len(q.queues)
+		q.mu.Unlock()
+		if left == 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("%d chat queues left after all updates were handled", left)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/timeparse"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Календарь живет в одном сообщении и перерисовывается кнопками:
+//
+//	cal:postID:2006-01            — показать месяц
+//	cal_day:postID:2006-01-02     — выбран день, показать часы
+//	cal_hour:postID:2006-01-02T15 — выбран час, показать минуты
+//	cal_min:postID:2006-01-02T15:04 — выбрано время, запланировать
+//	cal_nop:postID                — пустая клетка или заголовок
+const (
+	calMonthLayout = "2006-01"
+	calDayLayout   = "2006-01-02"
+	calHourLayout  = "2006-01-02T15"
+	calMinLayout   = "2006-01-02T15:04"
+	calMinuteStep  = 5
+)
+
+var monthNames = [...]string{"", "Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"}
+
+var weekdayShort = [...]string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+// calendarPrompt — текст сообщения с календарем.
+const calendarPrompt = "Когда опубликовать? Выберите день в календаре или напишите время.\n"
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func calNop(postID int64, label string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("cal_nop:%d", postID))
+}
+
+// calendarKeyboard строит сетку месяца month. Прошедшие дни недоступны.
+func calendarKeyboard(postID int64, month, now time.Time) tgbotapi.InlineKeyboardMarkup {
+	month = startOfMonth(month)
+	today := startOfDay(now)
+
+	prev := calNop(postID, " ")
+	if month.After(startOfMonth(now)) {
+		prev = tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("cal:%d:%s", postID, month.AddDate(0, -1, 0).Format(calMonthLayout)))
+	}
+	next := tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("cal:%d:%s", postID, month.AddDate(0, 1, 0).Format(calMonthLayout)))
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		{prev, calNop(postID, fmt.Sprintf("%s %d", monthNames[month.Month()], month.Year())), next},
+	}
+
+	var header []tgbotapi.InlineKeyboardButton
+	for _, name := range weekdayShort {
+		header = append(header, calNop(postID, name))
+	}
+	rows = append(rows, header)
+
+	// Неделя начинается с понедельника
+	week := make([]tgbotapi.InlineKeyboardButton, 0, 7)
+	for i := 0; i < (int(month.Weekday())+6)%7; i++ {
+		week = append(week, calNop(postID, " "))
+	}
+	for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+		label := fmt.Sprintf("%d", day.Day())
+		if day.Before(today) {
+			week = append(week, calNop(postID, "·"))
+		} else {
+			if day.Equal(today) {
+				label = "[" + label + "]"
+			}
+			week = append(week, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("cal_day:%d:%s", postID, day.Format(calDayLayout))))
+		}
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = make([]tgbotapi.InlineKeyboardButton, 0, 7)
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, calNop(postID, " "))
+		}
+		rows = append(rows, week)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(cancelScheduleButton(postID)))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// cancelScheduleButton прекращает ожидание даты: пост остается черновиком.
+func cancelScheduleButton(postID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("✖️ Не планировать", fmt.Sprintf("cal_cancel:%d", postID))
+}
+
+// hourKeyboard предлагает час публикации в выбранный день.
+func hourKeyboard(postID int64, day, now time.Time) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for hour := 0; hour < 24; hour++ {
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, day.Location())
+		if !at.Add(time.Hour).After(now) {
+			row = append(row, calNop(postID, "·"))
+		} else {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%02d", hour), fmt.Sprintf("cal_hour:%d:%s", postID, at.Format(calHourLayout))))
+		}
+		if len(row) == 6 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("« К календарю", fmt.Sprintf("cal:%d:%s", postID, day.Format(calMonthLayout))),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// minuteKeyboard предлагает минуты публикации в выбранный час.
+func minuteKeyboard(postID int64, hour, now time.Time) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for minute := 0; minute < 60; minute += calMinuteStep {
+		at := hour.Add(time.Duration(minute) * time.Minute)
+		if !at.After(now) {
+			row = append(row, calNop(postID, "·"))
+		} else {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(at.Format("15:04"), fmt.Sprintf("cal_min:%d:%s", postID, at.Format(calMinLayout))))
+		}
+		if len(row) == 4 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("« К часам", fmt.Sprintf("cal_day:%d:%s", postID, hour.Format(calDayLayout))),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleCalendarCallback перерисовывает календарь или планирует пост на выбранное время.
+func (h *Handler) handleCalendarCallback(update tgbotapi.Update, post domain.Post, action, arg string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+	messageID := update.CallbackQuery.Message.MessageID
+	loc := h.userUsecase.Location(chatID)
+	now := time.Now().In(loc)
+
+	var text string
+	var markup tgbotapi.InlineKeyboardMarkup
+	var err error
+	switch action {
+	case "cal_nop":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+		return
+
+	case "cal_cancel":
+		h.usecase.ClearPendingSchedule(chatID)
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Планирование отменено"))
+		h.api.Request(tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("Пост #%d не запланирован и остался черновиком.", post.ID)))
+		return
+
+	case "cal":
+		var month time.Time
+		if month, err = time.ParseInLocation(calMonthLayout, arg, loc); err == nil {
+			text = calendarPrompt + scheduleHint
+			markup = calendarKeyboard(post.ID, month, now)
+		}
+
+	case "cal_day":
+		var day time.Time
+		if day, err = time.ParseInLocation(calDayLayout, arg, loc); err == nil {
+			text = fmt.Sprintf("%s. Выберите час:", timeparse.FormatDay(day))
+			markup = hourKeyboard(post.ID, day, now)
+		}
+
+	case "cal_hour":
+		var hour time.Time
+		if hour, err = time.ParseInLocation(calHourLayout, arg, loc); err == nil {
+			text = fmt.Sprintf("%s. Выберите минуты:", timeparse.Format(hour))
+			markup = minuteKeyboard(post.ID, hour, now)
+		}
+
+	case "cal_min":
+		var at time.Time
+		if at, err = time.ParseInLocation(calMinLayout, arg, loc); err != nil {
+			break
+		}
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Планирование"))
+		h.api.Request(tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("Время: %s (%s)", timeparse.Format(at), loc)))
+		h.saveSchedule(chatID, post.ID, at)
+		return
+	}
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Неверная дата"))
+		return
+	}
+
+	h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+	h.api.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, markup))
+}
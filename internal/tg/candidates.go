@@ -0,0 +1,122 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/timeparse"
+	"lady/internal/usecase"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// parseCandidateCount отделяет число вариантов от темы в аргументах /generate:
+// "3 тема" — три варианта. Число вне 1..MaxCandidates считается частью темы,
+// чтобы «/generate 2025 год» не превращался в 2025 генераций.
+func parseCandidateCount(args string) (int, string, bool) {
+	first, topic, found := strings.Cut(args, " ")
+	if !found {
+		return 0, "", false
+	}
+	count, err := strconv.Atoi(first)
+	if err != nil || count < 1 || count > usecase.MaxCandidates {
+		return 0, "", false
+	}
+	return count, strings.TrimSpace(topic), true
+}
+
+// generateCandidates генерирует несколько вариантов текста по теме и показывает
+// их пронумерованными сообщениями с кнопкой выбора.
+func (h *Handler) generateCandidates(chatID int64, topic string, count int) {
+	if topic == "" {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Укажи тему: /generate <число вариантов> <тема>"))
+		return
+	}
+	h.usecase.ClearPendingInput(chatID)
+	h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Генерируем %d вариантов...", count)))
+	h.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+
+	channelID, _ := h.channelUsecase.DefaultChannel(chatID)
+	persona, err := h.personaUsecase.ForChannel(channelID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка генерации: %v", err)))
+		return
+	}
+	seriesID := h.seriesUsecase.Active(chatID)
+	candidates, err := h.generator(chatID, channelID, seriesID).GenerateCandidates(persona, topic, count, h.userUsecase.Location(chatID))
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка генерации: %v", err)))
+		return
+	}
+
+	var topicID int64
+	if t, err := h.usecase.FindTopic(topic); err == nil {
+		topicID = t.ID
+	}
+	post, variants, err := h.usecase.CreateCandidates(chatID, channelID, topicID, seriesID, candidates)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка сохранения поста"))
+		log.Printf("Ошибка сохранения вариантов для chatID %d: %v", chatID, err)
+		return
+	}
+	log.Printf("Сгенерировано %d вариантов поста %d для chatID %d", len(variants), post.ID, chatID)
+
+	for i, v := range variants {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Вариант %d из %d\n\n%s", i+1, len(variants), v.Text))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ Выбрать %d", i+1), fmt.Sprintf("pick:%d:%d", post.ID, v.ID)),
+		))
+		if _, err := h.api.Send(msg); err != nil {
+			log.Printf("Ошибка отправки варианта %d поста %d: %v", i+1, post.ID, err)
+		}
+	}
+}
+
+// pickCandidate делает выбранный вариант текстом черновика, дорисовывает к нему
+// картинки и показывает черновик с кнопками действий. Описания картинок и
+// время, предложенные моделью вместе с вариантом, используются как при
+// обычной генерации.
+func (h *Handler) pickCandidate(callbackID string, chatID int64, messageID int, post domain.Post, variantID int64) {
+	post, err := h.usecase.SelectVariant(post.ID, variantID)
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(callbackID, err.Error()))
+		return
+	}
+	var picked domain.PostVariant
+	if variants, err := h.usecase.ListVariants(post.ID); err == nil {
+		for _, v := range variants {
+			if v.ID == variantID {
+				picked = v
+			}
+		}
+	} else {
+		log.Printf("Ошибка получения вариантов поста %d: %v", post.ID, err)
+	}
+	h.api.Request(tgbotapi.NewCallback(callbackID, "Вариант выбран"))
+	h.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+
+	if len(post.Images) == 0 {
+		h.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatUploadPhoto))
+		channelID := post.ChannelID
+		if channelID == 0 {
+			channelID, _ = h.channelUsecase.DefaultChannel(chatID)
+		}
+		images, err := h.generateImages(chatID, channelID, post.Text, picked.ImagePrompts)
+		if err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		}
+		if len(images) > 0 {
+			if post, err = h.usecase.SetPostImages(post.ID, images); err != nil {
+				h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Картинки не сохранены: %v", err)))
+				return
+			}
+		}
+	}
+	h.sendPost(chatID, post)
+	if picked.PublishAt.After(time.Now()) {
+		h.confirmSchedule(chatID, post.ID, timeparse.Result{Time: picked.PublishAt.In(h.userUsecase.Location(chatID)), Reason: "Это время предложила модель."})
+	}
+}
@@ -0,0 +1,282 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"lady/internal/domain"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sendChannels показывает привязанные каналы с кнопками выбора канала по умолчанию и отвязки.
+func (h *Handler) sendChannels(chatID int64) {
+	text, markup, err := h.renderChannels(chatID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при получении каналов"))
+		log.Printf("Ошибка получения каналов: %v", err)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	h.api.Send(msg)
+}
+
+func (h *Handler) renderChannels(chatID int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	channels, err := h.channelUsecase.List()
+	if err != nil {
+		return "", nil, err
+	}
+	if len(channels) == 0 {
+		return "Каналы не привязаны. Добавьте бота администратором в канал и отправьте /bind @канал или /bind <ID канала>", nil, nil
+	}
+	defaultChannel, _ := h.channelUsecase.DefaultChannel(chatID)
+
+	var builder strings.Builder
+	builder.WriteString("Каналы для публикации (★ — по умолчанию):\n\n")
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, ch := range channels {
+		title := ch.Title
+		if ch.ID == defaultChannel {
+			title = "★ " + title
+		}
+		builder.WriteString(fmt.Sprintf("%s — %d\n", title, ch.ID))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("ch_default:%d", ch.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("Отвязать", fmt.Sprintf("ch_unbind:%d", ch.ID)),
+		))
+	}
+	builder.WriteString("\nНажмите на канал, чтобы сделать его каналом по умолчанию.")
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return builder.String(), &markup, nil
+}
+
+// bindChannel привязывает канал по @username или ID. Бот и редактор должны быть
+// администраторами канала.
+func (h *Handler) bindChannel(chatID, userID int64, args string) {
+	if args == "" {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Укажи канал: /bind @канал или /bind <ID канала>. Бот должен быть администратором канала."))
+		return
+	}
+
+	chatConfig := tgbotapi.ChatConfig{SuperGroupUsername: args}
+	if id, err := strconv.ParseInt(args, 10, 64); err == nil {
+		chatConfig = tgbotapi.ChatConfig{ChatID: id}
+	} else if !strings.HasPrefix(args, "@") {
+		chatConfig.SuperGroupUsername = "@" + args
+	}
+
+	chat, err := h.api.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: chatConfig})
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Канал не найден. Проверьте адрес и что бот добавлен в канал."))
+		log.Printf("Ошибка получения канала %s: %v", args, err)
+		return
+	}
+	if !chat.IsChannel() && !chat.IsSuperGroup() {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Публиковать можно только в канал или супергруппу."))
+		return
+	}
+
+	if !h.isChannelAdmin(chat.ID, h.api.Self.ID) {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Бот не является администратором канала. Добавьте его с правом публикации сообщений."))
+		return
+	}
+	if !h.isChannelAdmin(chat.ID, userID) {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Привязать канал может только его администратор."))
+		return
+	}
+
+	title := chat.Title
+	if title == "" {
+		title = args
+	}
+	if err := h.channelUsecase.Bind(chatID, domain.Channel{ID: chat.ID, Title: title}); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка привязки канала: %v", err)))
+		return
+	}
+	h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Канал «%s» привязан. Список каналов: /channels", title)))
+}
+
+// isChannelAdmin сообщает, администратор ли пользователь в канале.
+func (h *Handler) isChannelAdmin(channelID, userID int64) bool {
+	member, err := h.api.GetChatMember(tgbotapi.GetChatMemberConfig{ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: channelID, UserID: userID}})
+	if err != nil {
+		log.Printf("Ошибка проверки прав %d в канале %d: %v", userID, channelID, err)
+		return false
+	}
+	return member.IsAdministrator() || member.IsCreator()
+}
+
+// checkChannelAccess проверяет, что пользователь может распоряжаться каналом:
+// он привязал канал или администрирует его.
+func (h *Handler) checkChannelAccess(channelID, userID int64) error {
+	ch, err := h.channelUsecase.Get(channelID)
+	if err != nil {
+		return err
+	}
+	if ch.AddedBy == userID || h.isChannelAdmin(ch.ID, userID) {
+		return nil
+	}
+	return errors.New("каналом распоряжаются только привязавший его редактор и администраторы канала")
+}
+
+// unbindChannel отвязывает канал по ID.
+func (h *Handler) unbindChannel(chatID, userID int64, args string) {
+	channelID, err := strconv.ParseInt(args, 10, 64)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Укажи ID канала: /unbind <ID канала>. ID есть в списке /channels"))
+		return
+	}
+	if err := h.checkChannelAccess(channelID, userID); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Не удалось отвязать канал: %v", err)))
+		return
+	}
+	if err := h.channelUsecase.Unbind(channelID); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Не удалось отвязать канал: %v", err)))
+		return
+	}
+	h.api.Send(tgbotapi.NewMessage(chatID, "Канал отвязан"))
+}
+
+// handleChannelCallback обрабатывает кнопки списка каналов.
+func (h *Handler) handleChannelCallback(update tgbotapi.Update, action, arg string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+	channelID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Неизвестный канал"))
+		return
+	}
+
+	err = h.checkChannelAccess(channelID, update.CallbackQuery.From.ID)
+	if err == nil {
+		switch action {
+		case "ch_default":
+			err = h.channelUsecase.SetDefault(chatID, channelID)
+		case "ch_unbind":
+			err = h.channelUsecase.Unbind(channelID)
+		}
+	}
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, err.Error()))
+		return
+	}
+	h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Готово"))
+
+	text, markup, err := h.renderChannels(chatID)
+	if err != nil {
+		log.Printf("Ошибка получения каналов: %v", err)
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(chatID, update.CallbackQuery.Message.MessageID, text)
+	edit.ReplyMarkup = markup
+	h.api.Request(edit)
+}
+
+// pickChannel определяет канал для публикации поста среди каналов, которыми
+// распоряжается userID. Если таких каналов несколько, отправляет клавиатуру
+// выбора с кнопками "action:postID:channelID" и возвращает false.
+func (h *Handler) pickChannel(chatID, userID int64, post domain.Post, action string) (int64, bool) {
+	all, err := h.channelUsecase.List()
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при получении каналов"))
+		log.Printf("Ошибка получения каналов: %v", err)
+		return 0, false
+	}
+	if len(all) == 0 {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Нет привязанных каналов. Привяжите канал: /bind @канал"))
+		return 0, false
+	}
+	var channels []domain.Channel
+	for _, ch := range all {
+		if h.checkChannelAccess(ch.ID, userID) == nil {
+			channels = append(channels, ch)
+		}
+	}
+	switch len(channels) {
+	case 0:
+		h.api.Send(tgbotapi.NewMessage(chatID, "Нет каналов, в которые вы можете публиковать: их привязывают редактор или администраторы канала. Привяжите свой канал: /bind @канал"))
+		return 0, false
+	case 1:
+		return channels[0].ID, true
+	}
+
+	current := post.ChannelID
+	if current == 0 {
+		current, _ = h.channelUsecase.DefaultChannel(chatID)
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, ch := range channels {
+		title := ch.Title
+		if ch.ID == current {
+			title = "★ " + title
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("%s:%d:%d", action, post.ID, ch.ID)),
+		))
+	}
+	msg := tgbotapi.NewMessage(chatID, "Выберите канал:")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	h.api.Send(msg)
+	return 0, false
+}
+
+// markChannelChosen заменяет клавиатуру выбора канала на название выбранного канала.
+func (h *Handler) markChannelChosen(chatID int64, messageID int, channelID int64) {
+	title := strconv.FormatInt(channelID, 10)
+	if ch, err := h.channelUsecase.Get(channelID); err == nil {
+		title = ch.Title
+	}
+	h.api.Request(tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("Канал: %s", title)))
+}
+
+// setPostChannel переносит пост в канал, которым распоряжается userID. ID канала
+// приходит из кнопки, поэтому канал заново ищется в реестре и проверяются права.
+func (h *Handler) setPostChannel(userID int64, post domain.Post, channelID int64) error {
+	if err := h.checkChannelAccess(channelID, userID); err != nil {
+		return err
+	}
+	return h.usecase.SetPostChannel(post.ID, channelID)
+}
+
+// publishTo публикует пост в выбранный канал.
+func (h *Handler) publishTo(chatID, userID int64, post domain.Post, channelID int64) {
+	if err := h.setPostChannel(userID, post, channelID); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Пост нельзя опубликовать: %v", err)))
+		return
+	}
+	post.ChannelID = channelID
+	h.publishNow(chatID, post)
+}
+
+// askScheduleDate запоминает канал поста и показывает календарь; время можно и написать.
+func (h *Handler) askScheduleDate(chatID, userID int64, post domain.Post, channelID int64) {
+	if channelID != post.ChannelID {
+		if err := h.setPostChannel(userID, post, channelID); err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Пост нельзя запланировать: %v", err)))
+			return
+		}
+	}
+	if err := h.usecase.SavePendingSchedule(chatID, post.ID); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка при сохранении поста: %v", err)))
+		return
+	}
+	now := time.Now().In(h.userUsecase.Location(chatID))
+	msg := tgbotapi.NewMessage(chatID, calendarPrompt+scheduleHint)
+	msg.ReplyMarkup = calendarKeyboard(post.ID, now, now)
+	h.api.Send(msg)
+}
+
+// createDraft сохраняет черновик в канал редактора по умолчанию.
+func (h *Handler) createDraft(chatID, topicID, seriesID int64, text string, images []domain.PostImage) (domain.Post, error) {
+	channelID, err := h.channelUsecase.DefaultChannel(chatID)
+	if err != nil {
+		// Канал выберут позже, на кнопках публикации
+		channelID = 0
+	}
+	return h.usecase.CreateDraft(chatID, channelID, topicID, seriesID, text, images)
+}
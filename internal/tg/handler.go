@@ -5,71 +5,60 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
-	"lady/internal/gpt"
+	"lady/internal/domain"
+	"lady/internal/textlimit"
 	"lady/internal/usecase"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-const channelID = "-1002848619245" // ID канала для публикации
-
-// Handler обрабатывает входящие обновления Telegram.
+// Handler обрабатывает входящие обновления Telegram. Обновления разных чатов и
+// планировщик вызывают его одновременно. Это безопасно: usecase-ы после создания
+// не меняются (GenerateUsecase настраивается копиями через For), их состояние
+// живет в SQLite, UsageUsecase защищен своим mu, BotAPI рассчитан на
+// параллельные запросы, а единственное изменяемое поле Handler — albums — под
+// albumMu.
 type Handler struct {
-	api             *tgbotapi.BotAPI
-	usecase         *usecase.TopicUsecase
-	generateUsecase *usecase.GenerateUsecase
-	apiKey          string
+	api               *tgbotapi.BotAPI
+	usecase           *usecase.TopicUsecase
+	generateUsecase   *usecase.GenerateUsecase
+	channelUsecase    *usecase.ChannelUsecase
+	userUsecase       *usecase.UserUsecase
+	personaUsecase    *usecase.PersonaUsecase
+	mediaUsecase      *usecase.MediaUsecase
+	usageUsecase      *usecase.UsageUsecase
+	moderationUsecase *usecase.ModerationUsecase
+	seriesUsecase     *usecase.SeriesUsecase
+
+	// albums — таймеры ответа на альбомы фото, по media_group_id
+	albumMu sync.Mutex
+	albums  map[string]*time.Timer
 }
 
 // NewHandler создает новый экземпляр Handler.
-func NewHandler(api *tgbotapi.BotAPI, uc *usecase.TopicUsecase, tuc *usecase.GenerateUsecase, apiKey string) *Handler {
-	return &Handler{api: api, usecase: uc, generateUsecase: tuc, apiKey: apiKey}
-}
-
-// extractSentences разбивает текст на предложения.
-func extractSentences(text string) []string {
-	re := regexp.MustCompile(`[.!?]\s+`)
-	sentences := re.Split(text, -1)
-	var res []string
-	for _, s := range sentences {
-		s = strings.TrimSpace(s)
-		if s != "" {
-			res = append(res, s+".")
-		}
-	}
-	return res
-}
-
-// extractStart извлекает начальную часть текста (первые два предложения).
-func extractStart(text string) string {
-	sentences := extractSentences(text)
-	if len(sentences) < 2 {
-		return text
-	}
-	return strings.Join(sentences[:2], " ")
-}
-
-// extractMiddle извлекает среднюю часть текста.
-func extractMiddle(text string) string {
-	sentences := extractSentences(text)
-	n := len(sentences)
-	if n < 4 {
-		return strings.Join(sentences[n/2:], " ")
+func NewHandler(api *tgbotapi.BotAPI, uc *usecase.TopicUsecase, tuc *usecase.GenerateUsecase, cuc *usecase.ChannelUsecase, uuc *usecase.UserUsecase, puc *usecase.PersonaUsecase, muc *usecase.MediaUsecase, usc *usecase.UsageUsecase, moc *usecase.ModerationUsecase, suc *usecase.SeriesUsecase) *Handler {
+	return &Handler{
+		api:               api,
+		usecase:           uc,
+		generateUsecase:   tuc,
+		channelUsecase:    cuc,
+		userUsecase:       uuc,
+		personaUsecase:    puc,
+		mediaUsecase:      muc,
+		usageUsecase:      usc,
+		moderationUsecase: moc,
+		seriesUsecase:     suc,
+		albums:            make(map[string]*time.Timer),
 	}
-	midStart := n / 3
-	midEnd := midStart + 2
-	if midEnd > n {
-		midEnd = n
-	}
-	return strings.Join(sentences[midStart:midEnd], " ")
 }
 
 // HandleCommand обрабатывает команды.
@@ -78,17 +67,21 @@ func (h *Handler) HandleCommand(update tgbotapi.Update) {
 	command := update.Message.Command()
 	args := strings.TrimSpace(update.Message.CommandArguments())
 
-	// Convert channelID to int64
-	channelIDInt, err := strconv.ParseInt(channelID, 10, 64)
-	if err != nil {
-		log.Printf("Ошибка преобразования channelID %s в int64: %v", channelID, err)
-		h.api.Send(tgbotapi.NewMessage(chatID, "Внутренняя ошибка сервера"))
-		return
-	}
-
 	switch command {
 	case "start":
 		h.api.Send(tgbotapi.NewMessage(chatID, "Привет! Просто пришли мне тему, и я сгенерирую текст."))
+	case "channels":
+		h.sendChannels(chatID)
+	case "bind":
+		h.bindChannel(chatID, update.Message.From.ID, args)
+	case "unbind":
+		h.unbindChannel(chatID, update.Message.From.ID, args)
+	case "timezone":
+		h.setTimeZone(chatID, args)
+	case "persona":
+		h.handlePersona(chatID, args)
+	case "series":
+		h.handleSeries(chatID, args)
 	case "list":
 		topics, err := h.usecase.ListTopics()
 		if err != nil {
@@ -109,111 +102,59 @@ func (h *Handler) HandleCommand(update tgbotapi.Update) {
 
 	case "generate":
 		if args == "" {
-			h.api.Send(tgbotapi.NewMessage(chatID, "Укажи тему: /generate <тема>"))
+			h.api.Send(tgbotapi.NewMessage(chatID, "Укажи тему: /generate <тема> или /generate <число вариантов> <тема>"))
 			return
 		}
-		text, img1, img2, err := h.generatePostContent(args)
-		if err != nil {
-			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка генерации: %v", err)))
+		if count, topic, ok := parseCandidateCount(args); ok {
+			h.generateCandidates(chatID, topic, count)
 			return
 		}
-		log.Printf("Сгенерирован пост для chatID %d: Текст: %s, Фото1: %s, Фото2: %s", chatID, text, img1, img2)
-		msg := tgbotapi.NewMessage(chatID, text)
-		btn := tgbotapi.NewInlineKeyboardButtonData("Опубликовать", "publish")
-		btn1 := tgbotapi.NewInlineKeyboardButtonData("Редактировать", "edit")
-		btn2 := tgbotapi.NewInlineKeyboardButtonData("Запланировать", "schedule")
-		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(btn, btn1, btn2))
-		if _, err := h.api.Send(msg); err != nil {
-			log.Printf("Ошибка отправки сообщения: %v", err)
+		var topicID int64
+		if topic, err := h.usecase.FindTopic(args); err == nil {
+			topicID = topic.ID
 		}
-		// Сохраняем пост с фотографиями
-		if err := h.usecase.SavePendingPost(chatID, text, img1, img2, time.Time{}); err != nil {
-			log.Printf("Ошибка сохранения отложенного поста для chatID %d: %v", chatID, err)
-		}
-		h.api.Send(tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(img1)))
-		h.api.Send(tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(img2)))
+		h.generateDraftLive(chatID, topicID, args, "✍️ Пишу текст...")
 
 	case "publish_pending":
-		pendingPost, img1, img2, publishAt, err := h.usecase.GetPendingPost(chatID)
-		if err != nil || pendingPost == "" {
+		post, err := h.usecase.LatestDraft(chatID)
+		if err != nil {
 			h.api.Send(tgbotapi.NewMessage(chatID, "Нет отложенных постов"))
 			return
 		}
-		if publishAt.IsZero() {
-			if img1 == "" || img2 == "" {
-				h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка: изображения для поста отсутствуют"))
-				log.Printf("Ошибка: изображения отсутствуют для chatID %d", chatID)
-				return
-			}
-			// Truncate caption to 1024 characters
-			caption := pendingPost
-			if len(caption) > 1024 {
-				caption = caption[:1024]
-				log.Printf("Текст поста для chatID %d укорочен до 1024 символов", chatID)
-			}
-			log.Printf("Длина подписи для chatID %d: %d символов", chatID, len(caption))
-			mediaGroup := tgbotapi.NewMediaGroup(channelIDInt, []interface{}{
-				tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(img1)),
-				tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(img2)),
-			})
-			// Correct type assertion
-			media := mediaGroup.Media[0].(tgbotapi.InputMediaPhoto)
-			media.Caption = caption
-			mediaGroup.Media[0] = media
-			if _, err := h.api.Send(mediaGroup); err != nil {
-				h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка публикации отложенного поста"))
-				log.Printf("Ошибка публикации: %v", err)
-			} else {
-				notifyMsg := tgbotapi.NewMessage(chatID, "Отложенный пост с фотографиями опубликован!")
-				if len(pendingPost) > 1024 {
-					notifyMsg.Text += "\nВнимание: текст поста был укорочен из-за ограничений Telegram."
-				}
-				h.api.Send(notifyMsg)
-				h.usecase.ClearPendingPost(chatID)
-			}
-		} else {
-			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Пост запланирован на %s", publishAt.Format("02.01.2006 15:04"))))
-		}
+		h.publishNow(chatID, post)
 
 	case "schedule":
 		if args == "" {
-			h.api.Send(tgbotapi.NewMessage(chatID, "Укажи дату и время: /schedule <DD.MM.YYYY HH:MM> (например, 11.08.2025 17:30)"))
+			h.api.Send(tgbotapi.NewMessage(chatID, "Укажи время: /schedule <когда>. "+scheduleHint))
 			return
 		}
-		pendingPost, img1, img2, _, err := h.usecase.GetPendingPost(chatID)
-		if err != nil || pendingPost == "" {
-			h.api.Send(tgbotapi.NewMessage(chatID, "Нет отложенного поста для планирования. Сначала сгенерируйте пост."))
-			return
-		}
-		log.Printf("Попытка запланировать пост для chatID %d с временем: %s", chatID, args)
-		// Use Asia/Novosibirsk timezone
-		loc, err := time.LoadLocation("Asia/Novosibirsk")
-		if err != nil {
-			log.Printf("Ошибка загрузки часового пояса Asia/Novosibirsk: %v", err)
-			h.api.Send(tgbotapi.NewMessage(chatID, "Внутренняя ошибка сервера"))
-			return
-		}
-		publishAt, err := time.ParseInLocation("02.01.2006 15:04", args, loc)
+		postID, err := h.usecase.GetPendingSchedule(chatID)
 		if err != nil {
-			h.api.Send(tgbotapi.NewMessage(chatID, "Неверный формат даты и времени. Используйте: DD.MM.YYYY HH:MM (например, 11.08.2025 17:30)"))
-			log.Printf("Ошибка парсинга времени '%s': %v", args, err)
-			return
-		}
-		if err := h.usecase.SavePendingPost(chatID, pendingPost, img1, img2, publishAt); err != nil {
-			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка при планировании поста: %v", err)))
-			log.Printf("Ошибка сохранения отложенного поста для chatID %d: %v", chatID, err)
-		} else {
-			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Пост с фотографиями запланирован на %s", publishAt.Format("02.01.2006 15:04"))))
-			log.Printf("Пост для chatID %d запланирован на %s", chatID, publishAt.Format("02.01.2006 15:04"))
+			post, err := h.usecase.LatestDraft(chatID)
+			if err != nil {
+				h.api.Send(tgbotapi.NewMessage(chatID, "Нет отложенного поста для планирования. Сначала сгенерируйте пост."))
+				return
+			}
+			postID = post.ID
 		}
+		h.schedulePost(chatID, postID, args)
+
+	case "queue":
+		h.sendQueue(chatID)
+
+	case "usage":
+		h.sendUsage(chatID)
 
 	case "list_pending": // Added for debugging
-		pendingPost, img1, img2, publishAt, err := h.usecase.GetPendingPost(chatID)
-		if err != nil || pendingPost == "" {
+		post, err := h.usecase.LatestDraft(chatID)
+		if err != nil {
 			h.api.Send(tgbotapi.NewMessage(chatID, "Нет отложенных постов"))
 			return
 		}
-		msg := fmt.Sprintf("Отложенный пост:\nТекст: %s\nДлина текста: %d символов\nФото1: %s\nФото2: %s\nВремя: %s", pendingPost, len(pendingPost), img1, img2, publishAt.Format("02.01.2006 15:04"))
+		msg := fmt.Sprintf("Отложенный пост #%d:\nТекст: %s\nДлина текста: %d символов", post.ID, post.Text, textlimit.Len(post.Text))
+		for i, img := range post.Images {
+			msg += fmt.Sprintf("\nФото%d: %s", i+1, img.URL)
+		}
 		h.api.Send(tgbotapi.NewMessage(chatID, msg))
 
 	default:
@@ -226,75 +167,76 @@ func (h *Handler) HandleCommand(update tgbotapi.Update) {
 func (h *Handler) HandleText(update tgbotapi.Update) {
 	chatID := update.Message.Chat.ID
 	text := strings.TrimSpace(update.Message.Text)
-	if len(text) < 3 {
-		h.api.Send(tgbotapi.NewMessage(chatID, "Тема слишком короткая, попробуйте другую"))
+	if text == "" {
+		return
+	}
+
+	// Проверяем, ожидается ли инструкция для AI-правки
+	if postID, err := h.usecase.GetPendingAIEdit(chatID); err == nil {
+		if err := usecase.CheckEditInstruction(text); err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("%v. %s", err, aiEditHint)))
+			return
+		}
+		h.usecase.ClearPendingAIEdit(chatID)
+		h.applyAIEdit(chatID, postID, text)
 		return
 	}
 
 	// Проверяем, ожидается ли редактирование
-	if pendingEdit, messageID, err := h.usecase.GetPendingEdit(chatID); err == nil && pendingEdit != "" {
-		editMsg := tgbotapi.NewEditMessageTextAndMarkup(
-			chatID,
-			messageID,
-			text,
-			tgbotapi.NewInlineKeyboardMarkup(
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("Опубликовать", "publish"),
-					tgbotapi.NewInlineKeyboardButtonData("Редактировать", "edit"),
-					tgbotapi.NewInlineKeyboardButtonData("Запланировать", "schedule"),
-				),
-			),
-		)
-		if _, err := h.api.Request(editMsg); err != nil {
+	if postID, messageID, err := h.usecase.GetPendingEdit(chatID); err == nil {
+		if err := h.usecase.UpdatePostText(postID, text); err != nil {
 			h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка обновления текста"))
-			log.Printf("Ошибка редактирования: %v", err)
-		} else {
-			h.api.Send(tgbotapi.NewMessage(chatID, "Текст поста обновлен!"))
+			log.Printf("Ошибка обновления черновика %d: %v", postID, err)
 			h.usecase.ClearPendingEdit(chatID)
+			return
 		}
+		if post, err := h.usecase.GetPost(chatID, postID); err == nil {
+			editMsg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, h.postKeyboard(post))
+			if _, err := h.api.Request(editMsg); err != nil {
+				// Сообщение могло устареть — показываем пост заново
+				log.Printf("Ошибка редактирования: %v", err)
+				h.sendPost(chatID, post)
+			}
+		}
+		h.api.Send(tgbotapi.NewMessage(chatID, "Текст поста обновлен!"))
+		h.usecase.ClearPendingEdit(chatID)
+		h.warnLongText(chatID, postID, text)
 		return
 	}
 
-	// Сохраняем новую тему
-	if err := h.usecase.AddTopic(text); err != nil {
-		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка сохранения темы"))
-		log.Printf("Ошибка сохранения темы: %v", err)
+	// Проверяем, ожидается ли новое описание картинки
+	if postID, mediaID, messageID, err := h.usecase.GetPendingImagePrompt(chatID); err == nil {
+		h.usecase.ClearPendingImagePrompt(chatID)
+		h.applyImagePrompt(chatID, postID, mediaID, messageID, text)
 		return
 	}
 
-	h.api.Send(tgbotapi.NewMessage(chatID, "Тема сохранена! Генерируем текст..."))
-
-	// Эффект печати
-	if _, err := h.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping)); err != nil {
-		log.Printf("Ошибка отправки ChatAction: %v", err)
+	// Проверяем, ожидается ли дата публикации
+	if postID, err := h.usecase.GetPendingSchedule(chatID); err == nil {
+		h.schedulePost(chatID, postID, text)
+		return
 	}
-	time.Sleep(1500 * time.Millisecond)
 
-	// Генерируем контент
-	text, img1, img2, err := h.generatePostContent(text)
-	if err != nil {
-		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+	// Короткий ответ вроде «ок» годится для правки или даты, но не для темы
+	if utf8.RuneCountInString(text) < 3 {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Тема слишком короткая, попробуйте другую"))
 		return
 	}
 
-	// Отправляем сгенерированный текст с кнопками
-	msg := tgbotapi.NewMessage(chatID, text)
-	btn := tgbotapi.NewInlineKeyboardButtonData("Опубликовать", "publish")
-	btn1 := tgbotapi.NewInlineKeyboardButtonData("Редактировать", "edit")
-	btn2 := tgbotapi.NewInlineKeyboardButtonData("Запланировать", "schedule")
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(btn, btn1, btn2))
-	if _, err := h.api.Send(msg); err != nil {
-		log.Printf("Ошибка отправки сообщения: %v", err)
+	// Сохраняем новую тему
+	if err := h.usecase.AddTopic(text); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка сохранения темы"))
+		log.Printf("Ошибка сохранения темы: %v", err)
+		return
 	}
 
-	// Сохраняем пост с фотографиями как отложенный
-	if err := h.usecase.SavePendingPost(chatID, text, img1, img2, time.Time{}); err != nil {
-		log.Printf("Ошибка сохранения отложенного поста для chatID %d: %v", chatID, err)
+	var topicID int64
+	if topic, err := h.usecase.FindTopic(text); err == nil {
+		topicID = topic.ID
 	}
 
-	// Отправляем картинки
-	h.api.Send(tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(img1)))
-	h.api.Send(tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(img2)))
+	// Текст появляется в заглушке по мере генерации, кнопки — когда пост готов
+	h.generateDraftLive(chatID, topicID, text, "Тема сохранена! ✍️ Пишу текст...")
 }
 
 // HandleFile обрабатывает загруженные файлы.
@@ -418,106 +360,251 @@ func downloadFile(filepath string, url string) error {
 }
 
 // HandleCallback обрабатывает callback-запросы от кнопок.
+// Данные кнопки имеют вид "action:postID[:arg]"; кнопки старых сообщений без ID
+// относятся к последнему черновику чата.
 func (h *Handler) HandleCallback(update tgbotapi.Update) {
 	data := update.CallbackQuery.Data
 	chatID := update.CallbackQuery.Message.Chat.ID
 	messageID := update.CallbackQuery.Message.MessageID
-	msgText := update.CallbackQuery.Message.Text
 
-	// Convert channelID to int64
-	channelIDInt, err := strconv.ParseInt(channelID, 10, 64)
-	if err != nil {
-		log.Printf("Ошибка преобразования channelID %s в int64: %v", channelID, err)
-		h.api.Send(tgbotapi.NewMessage(chatID, "Внутренняя ошибка сервера"))
+	action, rest, _ := strings.Cut(data, ":")
+	if strings.HasPrefix(action, "ch_") {
+		h.handleChannelCallback(update, action, rest)
+		return
+	}
+	if action == "tz" {
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+		h.setTimeZone(chatID, rest)
 		return
 	}
 
-	switch data {
-	case "publish":
-		_, img1, img2, _, err := h.usecase.GetPendingPost(chatID)
-		if err != nil {
-			log.Printf("Ошибка получения отложенного поста для chatID %d: %v", chatID, err)
-			img1, img2 = "", ""
-		}
-		if img1 == "" || img2 == "" {
-			h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка: изображения для поста отсутствуют"))
-			return
+	idStr, arg, _ := strings.Cut(rest, ":")
+	var post domain.Post
+	var err error
+	if idStr == "" {
+		post, err = h.usecase.LatestDraft(chatID)
+	} else {
+		var postID int64
+		postID, err = strconv.ParseInt(idStr, 10, 64)
+		if err == nil {
+			post, err = h.usecase.GetPost(chatID, postID)
 		}
+	}
+	if err != nil {
+		log.Printf("Ошибка получения поста по callback %q для chatID %d: %v", data, chatID, err)
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Пост не найден"))
+		return
+	}
 
-		caption := msgText
-		if len(caption) > 1024 {
-			caption = caption[:1024]
-			log.Printf("Текст поста укорочен до 1024 символов")
+	switch action {
+	case "publish":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Публикация"))
+		if channelID, ok := h.pickChannel(chatID, update.CallbackQuery.From.ID, post, "pubto"); ok {
+			h.publishTo(chatID, update.CallbackQuery.From.ID, post, channelID)
 		}
 
-		mediaGroup := tgbotapi.NewMediaGroup(channelIDInt, []interface{}{
-			tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(img1)),
-			tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(img2)),
-		})
-
-		media := mediaGroup.Media[0].(tgbotapi.InputMediaPhoto)
-		media.Caption = caption
-		mediaGroup.Media[0] = media
-
-		if _, err := h.api.Send(mediaGroup); err != nil {
-			h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка публикации в канал"))
-			log.Printf("Ошибка публикации: %v", err)
-		} else {
-			notifyMsg := tgbotapi.NewMessage(chatID, "Пост с фотографиями успешно опубликован в канале!")
-			if len(msgText) > 1024 {
-				notifyMsg.Text += "\nВнимание: текст был укорочен."
-			}
-			h.api.Send(notifyMsg)
-			h.usecase.ClearPendingPost(chatID)
+	case "pubto":
+		channelID, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Неизвестный канал"))
+			return
 		}
-
-		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "Опубликовано")
-		h.api.Request(callback)
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Публикация"))
+		h.markChannelChosen(chatID, messageID, channelID)
+		h.publishTo(chatID, update.CallbackQuery.From.ID, post, channelID)
 
 	case "edit":
 		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "Редактирование")
 		h.api.Request(callback)
-		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Текущий текст:\n%s\n\nОтправьте новый текст для замены.", msgText)))
-		if err := h.usecase.SavePendingEdit(chatID, messageID, msgText); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Текущий текст:\n%s\n\nОтправьте новый текст для замены.", post.Text)))
+		h.usecase.ClearPendingAIEdit(chatID)
+		if err := h.usecase.SavePendingEdit(chatID, post.ID, messageID); err != nil {
 			h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при сохранении данных для редактирования"))
 			log.Printf("Ошибка сохранения редактирования: %v", err)
 		}
 
 	case "schedule":
-		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "Планирование")
-		h.api.Request(callback)
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Планирование"))
+		if channelID, ok := h.pickChannel(chatID, update.CallbackQuery.From.ID, post, "schedto"); ok {
+			h.askScheduleDate(chatID, update.CallbackQuery.From.ID, post, channelID)
+		}
 
-		_, img1, img2, _, err := h.usecase.GetPendingPost(chatID)
+	case "schedto":
+		channelID, err := strconv.ParseInt(arg, 10, 64)
 		if err != nil {
-			log.Printf("Ошибка получения отложенного поста: %v", err)
-			img1, img2 = "", ""
+			h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Неизвестный канал"))
+			return
 		}
-		if err := h.usecase.SavePendingPost(chatID, msgText, img1, img2, time.Time{}); err != nil {
-			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка при сохранении поста: %v", err)))
-		} else {
-			h.api.Send(tgbotapi.NewMessage(chatID, "Отправьте дату и время публикации (DD.MM.YYYY HH:MM): /schedule <дата и время> (например, 11.08.2025 17:30)"))
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Планирование"))
+		h.markChannelChosen(chatID, messageID, channelID)
+		h.askScheduleDate(chatID, update.CallbackQuery.From.ID, post, channelID)
+
+	case "cal", "cal_day", "cal_hour", "cal_min", "cal_nop", "cal_cancel":
+		h.handleCalendarCallback(update, post, action, arg)
+
+	case "sched_ok":
+		unix, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Неверное время"))
+			return
 		}
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Планирование"))
+		h.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+		h.saveSchedule(chatID, post.ID, time.Unix(unix, 0).In(h.userUsecase.Location(chatID)))
+
+	case "sched_other":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+		h.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+		h.askScheduleDate(chatID, update.CallbackQuery.From.ID, post, post.ChannelID)
+
+	case "regen":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Генерируем новый вариант"))
+		h.regeneratePost(chatID, messageID, post)
+
+	case "variant":
+		variantID, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Неизвестный вариант"))
+			return
+		}
+		h.selectVariant(update.CallbackQuery.ID, chatID, messageID, post, variantID)
+
+	case "pick":
+		variantID, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Неизвестный вариант"))
+			return
+		}
+		h.pickCandidate(update.CallbackQuery.ID, chatID, messageID, post, variantID)
+
+	case "images", "img_show", "img_up", "img_down", "img_del":
+		h.handleImageCallback(update, post, action, arg)
+
+	case "img_regen", "img_prompt", "img_rm":
+		h.handleImagePreviewCallback(update, post, action, arg)
+
+	case "ai_edit", "ai_ok", "ai_undo":
+		h.handleAIEditCallback(update, post, action, arg)
+
+	case "shorten":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Сокращаем"))
+		h.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+		h.shortenPost(chatID, post)
+
+	case "q_resched":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Перенос"))
+		h.askScheduleDate(chatID, update.CallbackQuery.From.ID, post, post.ChannelID)
+
+	case "q_open":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+		if post.Scheduled() {
+			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Пост #%d запланирован на %s", post.ID, post.PublishAt.In(h.userUsecase.Location(chatID)).Format("02.01.2006 15:04"))))
+		}
+		h.sendPost(chatID, post)
+
+	case "q_cancel":
+		if err := h.usecase.CancelPost(post.ID); err != nil {
+			h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, err.Error()))
+			return
+		}
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Пост отменен"))
+		h.refreshQueue(chatID, messageID)
+
+	case "retry":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Повторная публикация"))
+		h.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+		h.publishNow(chatID, post)
+
+	case "mod_pub":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Публикация"))
+		h.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+		h.publishFlagged(chatID, post)
+
+	case "cancel":
+		if err := h.usecase.CancelPost(post.ID); err != nil {
+			h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, err.Error()))
+			return
+		}
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Пост отменен"))
+		h.api.Request(tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("Пост #%d отменен", post.ID)))
+
+	case "q_pub":
+		h.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Публикация"))
+		h.publishNow(chatID, post)
+		h.refreshQueue(chatID, messageID)
 	}
 }
 
-// generatePostContent генерирует текст и изображения для поста.
-func (h *Handler) generatePostContent(topic string) (string, string, string, error) {
-	text, err := h.generateUsecase.GenerateFromTopic(topic)
+// generator возвращает генератор, который списывает расход на редактора и
+// канал. Пост серии seriesID получает пересказ ее опубликованных постов; если
+// пересказать не удалось, пост пишется без него.
+func (h *Handler) generator(chatID, channelID, seriesID int64) *usecase.GenerateUsecase {
+	gen := h.generateUsecase.For(chatID, channelID)
+	if seriesID == 0 {
+		return gen
+	}
+	series, err := h.seriesUsecase.Get(seriesID)
 	if err != nil {
-		return "", "", "", fmt.Errorf("ошибка генерации текста: %w", err)
+		log.Printf("Ошибка получения серии %d: %v", seriesID, err)
+		return gen
 	}
+	previous, err := h.seriesUsecase.Previous(seriesID)
+	if err != nil {
+		log.Printf("Ошибка получения постов серии %d: %v", seriesID, err)
+		return gen
+	}
+	next, err := gen.InSeries(series, previous)
+	if err != nil {
+		log.Printf("Серия %d: %v", seriesID, err)
+		return gen
+	}
+	return next
+}
 
-	startPrompt := extractStart(text)
-	middlePrompt := extractMiddle(text)
-
-	img1, err := gpt.GenerateImage(h.apiKey, startPrompt)
+// writePost пишет пост голосом персоны канала и укладывает текст в подпись.
+// Если задан onProgress, текст отдается в него по мере ответа модели.
+func (h *Handler) writePost(gen *usecase.GenerateUsecase, chatID, channelID int64, topic string, onProgress func(text string)) (usecase.GeneratedPost, error) {
+	persona, err := h.personaUsecase.ForChannel(channelID)
 	if err != nil {
-		return "", "", "", fmt.Errorf("ошибка генерации первой картинки: %w", err)
+		return usecase.GeneratedPost{}, err
 	}
-	img2, err := gpt.GenerateImage(h.apiKey, middlePrompt)
+	post, err := gen.WritePost(persona, topic, h.userUsecase.Location(chatID), onProgress)
 	if err != nil {
-		return "", "", "", fmt.Errorf("ошибка генерации второй картинки: %w", err)
+		return usecase.GeneratedPost{}, err
 	}
+	if condensed, err := gen.Condense(post.Text, textlimit.Caption); err == nil {
+		post.Text = condensed
+	} else {
+		// Не страшно: при публикации остаток уйдет ответом
+		log.Printf("Ошибка сокращения текста для канала %d: %v", channelID, err)
+	}
+	return post, nil
+}
 
-	return text, img1, img2, nil
+// generateImages просит модель описать картинки к тексту в стиле персоны канала,
+// рисует их и сразу скачивает в хранилище: ссылки генератора живут около часа.
+// Сколько картинок нужно, решает персона. Готовые описания prompts, например
+// из ответа в формате json, рисуются как есть. Если часть картинок не удалась,
+// возвращает остальные вместе с ошибкой.
+func (h *Handler) generateImages(chatID, channelID int64, text string, prompts []string) ([]domain.PostImage, error) {
+	gen := h.generateUsecase.For(chatID, channelID)
+	if len(prompts) == 0 {
+		persona, err := h.personaUsecase.ForChannel(channelID)
+		if err != nil {
+			return nil, err
+		}
+		if prompts, err = gen.ImagePrompts(persona, text, persona.ImageCount); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("Описания картинок для канала %d: %q", channelID, prompts)
+	images, drawErr := gen.GenerateImages(prompts)
+	if len(images) == 0 {
+		return nil, drawErr
+	}
+	images, err := h.mediaUsecase.Localize(images)
+	if err != nil {
+		return nil, err
+	}
+	return images, drawErr
 }
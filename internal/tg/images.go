@@ -0,0 +1,157 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/usecase"
+	"log"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// imageKeyboard возвращает кнопки под превью картинки. Картинка указывается
+// файлом, а не номером: номера сдвигаются, когда соседние картинки удаляют.
+func imageKeyboard(postID, mediaID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔄 Перерисовать", fmt.Sprintf("img_regen:%d:%d", postID, mediaID)),
+		tgbotapi.NewInlineKeyboardButtonData("✏️ Описание", fmt.Sprintf("img_prompt:%d:%d", postID, mediaID)),
+		tgbotapi.NewInlineKeyboardButtonData("🗑", fmt.Sprintf("img_rm:%d:%d", postID, mediaID)),
+	))
+}
+
+// sendImagePreview отправляет картинку черновика с кнопками. Картинки старых
+// постов, не скачанные в хранилище, показываются без кнопок.
+func (h *Handler) sendImagePreview(chatID, postID int64, img domain.PostImage) error {
+	photo := tgbotapi.NewPhoto(chatID, photoData(img))
+	if img.MediaID != 0 {
+		photo.ReplyMarkup = imageKeyboard(postID, img.MediaID)
+	}
+	_, err := h.uploadPhoto(photo, img)
+	return err
+}
+
+// handleImagePreviewCallback обрабатывает кнопки под превью картинки.
+func (h *Handler) handleImagePreviewCallback(update tgbotapi.Update, post domain.Post, action, arg string) {
+	callbackID := update.CallbackQuery.ID
+	chatID := update.CallbackQuery.Message.Chat.ID
+	messageID := update.CallbackQuery.Message.MessageID
+
+	mediaID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Картинка не найдена"))
+		return
+	}
+	index, err := usecase.ImageIndex(post, mediaID)
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(callbackID, err.Error()))
+		return
+	}
+
+	switch action {
+	case "img_regen":
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Перерисовываем"))
+		h.regenerateImage(chatID, messageID, post, index, post.Images[index].Prompt)
+
+	case "img_prompt":
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Описание картинки"))
+		current := post.Images[index].Prompt
+		if current == "" {
+			current = "нет — картинку прислал редактор"
+		}
+		if err := h.usecase.SavePendingImagePrompt(chatID, post.ID, mediaID, messageID); err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при сохранении данных для редактирования"))
+			return
+		}
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"Текущее описание картинки %d:\n%s\n\nОтправьте новое описание, лучше на английском — картинка перерисуется по нему.", index+1, current)))
+
+	case "img_rm":
+		if _, err := h.usecase.RemoveImage(post.ID, index); err != nil {
+			h.api.Request(tgbotapi.NewCallback(callbackID, err.Error()))
+			return
+		}
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Картинка удалена"))
+		if _, err := h.api.Request(tgbotapi.NewDeleteMessage(chatID, messageID)); err != nil {
+			log.Printf("Ошибка удаления превью картинки поста %d: %v", post.ID, err)
+		}
+	}
+}
+
+// applyImagePrompt перерисовывает картинку по описанию, которое прислал редактор.
+func (h *Handler) applyImagePrompt(chatID, postID, mediaID int64, messageID int, prompt string) {
+	post, err := h.usecase.GetPost(chatID, postID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+	index, err := usecase.ImageIndex(post, mediaID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+	h.regenerateImage(chatID, messageID, post, index, prompt)
+}
+
+// regenerateImage рисует картинку index заново и заменяет ее в посте и в превью.
+// Для фото редактора описания нет — его сначала придумывает модель по тексту поста.
+func (h *Handler) regenerateImage(chatID int64, messageID int, post domain.Post, index int, prompt string) {
+	h.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatUploadPhoto))
+	img, err := h.drawImage(chatID, post, prompt)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Картинка не перерисована: %v", err)))
+		return
+	}
+	if _, err := h.usecase.ReplaceImage(post.ID, index, img); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Картинка не сохранена: %v", err)))
+		return
+	}
+
+	markup := imageKeyboard(post.ID, img.MediaID)
+	edit := tgbotapi.EditMessageMediaConfig{
+		BaseEdit: tgbotapi.BaseEdit{ChatID: chatID, MessageID: messageID, ReplyMarkup: &markup},
+		Media:    tgbotapi.NewInputMediaPhoto(photoData(img)),
+	}
+	msg, err := h.api.Send(edit)
+	if err != nil {
+		// Превью могло устареть — покажем картинку новым сообщением
+		log.Printf("Ошибка обновления превью картинки поста %d: %v", post.ID, err)
+		if err := h.sendImagePreview(chatID, post.ID, img); err != nil {
+			log.Printf("Ошибка отправки картинки поста %d: %v", post.ID, err)
+		}
+		return
+	}
+	if img.FileID == "" {
+		h.mediaUsecase.RememberFileID(img.MediaID, photoFileID(msg))
+	}
+}
+
+// drawImage рисует одну картинку к посту и сохраняет ее в хранилище. Кэш
+// ответов не используется: по тому же описанию нужна новая картинка.
+func (h *Handler) drawImage(chatID int64, post domain.Post, prompt string) (domain.PostImage, error) {
+	channelID := post.ChannelID
+	if channelID == 0 {
+		channelID, _ = h.channelUsecase.DefaultChannel(chatID)
+	}
+	gen := h.generateUsecase.For(chatID, channelID).Fresh()
+	if prompt == "" {
+		persona, err := h.personaUsecase.ForChannel(channelID)
+		if err != nil {
+			return domain.PostImage{}, err
+		}
+		prompts, err := gen.ImagePrompts(persona, post.Text, 1)
+		if err != nil {
+			return domain.PostImage{}, err
+		}
+		prompt = prompts[0]
+	}
+	images, err := gen.GenerateImages([]string{prompt})
+	if err != nil {
+		return domain.PostImage{}, err
+	}
+	images, err = h.mediaUsecase.Localize(images)
+	if err != nil {
+		return domain.PostImage{}, err
+	}
+	return images[0], nil
+}
@@ -0,0 +1,57 @@
+package tg
+
+import (
+	"lady/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// photoData выбирает, как отправить картинку: по file_id, если Telegram уже
+// видел файл, загрузкой из хранилища или, для старых постов, по исходному URL.
+func photoData(img domain.PostImage) tgbotapi.RequestFileData {
+	switch {
+	case img.FileID != "":
+		return tgbotapi.FileID(img.FileID)
+	case img.Path != "":
+		return tgbotapi.FilePath(img.Path)
+	default:
+		return tgbotapi.FileURL(img.URL)
+	}
+}
+
+// photoFileID возвращает file_id самого большого размера фото из сообщения.
+func photoFileID(msg tgbotapi.Message) string {
+	if len(msg.Photo) == 0 {
+		return ""
+	}
+	return msg.Photo[len(msg.Photo)-1].FileID
+}
+
+// sendPhoto отправляет картинку и запоминает ее file_id после первой загрузки.
+func (h *Handler) sendPhoto(chatID int64, img domain.PostImage, caption string) (tgbotapi.Message, error) {
+	photo := tgbotapi.NewPhoto(chatID, photoData(img))
+	photo.Caption = caption
+	return h.uploadPhoto(photo, img)
+}
+
+// uploadPhoto отправляет подготовленное фото картинки img и запоминает file_id.
+func (h *Handler) uploadPhoto(photo tgbotapi.PhotoConfig, img domain.PostImage) (tgbotapi.Message, error) {
+	msg, err := h.api.Send(photo)
+	if err != nil {
+		return msg, err
+	}
+	if img.FileID == "" {
+		h.mediaUsecase.RememberFileID(img.MediaID, photoFileID(msg))
+	}
+	return msg, nil
+}
+
+// rememberFileIDs запоминает file_id картинок, отправленных медиа-группой.
+func (h *Handler) rememberFileIDs(images []domain.PostImage, messages []tgbotapi.Message) {
+	for i, img := range images {
+		if i >= len(messages) || img.FileID != "" {
+			continue
+		}
+		h.mediaUsecase.RememberFileID(img.MediaID, photoFileID(messages[i]))
+	}
+}
@@ -0,0 +1,62 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// moderationKeyboard возвращает кнопки для поста, на котором сработала
+// модерация: отмеченный пост можно опубликовать под свою ответственность,
+// заблокированный — только исправить или отменить.
+func moderationKeyboard(postID int64, action domain.ModerationAction) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if action == domain.ModerationFlag {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚀 Опубликовать всё равно", fmt.Sprintf("mod_pub:%d", postID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✏️ Редактировать", fmt.Sprintf("edit:%d", postID)),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", fmt.Sprintf("cancel:%d", postID)),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// sendModeration сообщает редактору, какие правила модерации сработали на посте.
+func (h *Handler) sendModeration(chatID int64, post domain.Post, verdict domain.ModerationVerdict) {
+	var builder strings.Builder
+	if verdict.Action == domain.ModerationBlock {
+		builder.WriteString(fmt.Sprintf("⛔ Пост #%d не опубликован: его заблокировала модерация.\n", post.ID))
+	} else {
+		builder.WriteString(fmt.Sprintf("⚠️ Пост #%d отмечен модерацией и ждет вашего решения.\n", post.ID))
+	}
+	builder.WriteString("Сработали правила:\n")
+	for _, reason := range verdict.Reasons {
+		builder.WriteString("- " + reason + "\n")
+	}
+	builder.WriteString("\n" + preview(post.Text, queuePreviewLen))
+
+	msg := tgbotapi.NewMessage(chatID, builder.String())
+	msg.ReplyMarkup = moderationKeyboard(post.ID, verdict.Action)
+	if _, err := h.api.Send(msg); err != nil {
+		log.Printf("Ошибка отправки результата модерации chatID %d: %v", chatID, err)
+	}
+}
+
+// publishFlagged публикует отмеченный модерацией пост по решению редактора.
+// Правила проверяются заново: после правки пост мог попасть под блокировку.
+func (h *Handler) publishFlagged(chatID int64, post domain.Post) {
+	verdict := h.moderationUsecase.Check(post)
+	if verdict.Action == domain.ModerationBlock {
+		h.sendModeration(chatID, post, verdict)
+		return
+	}
+	if verdict.Action == domain.ModerationFlag {
+		log.Printf("Редактор %d публикует отмеченный пост %d: %s", chatID, post.ID, verdict.Summary())
+	}
+	h.publishApproved(chatID, post)
+}
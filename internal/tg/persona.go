@@ -0,0 +1,289 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/usecase"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const personaHelp = `Персоны — голоса каналов. Команды:
+/persona — список персон
+/persona show <имя> — показать шаблон
+/persona new <имя> — создать, поля с новой строки
+/persona edit <имя> — изменить указанные поля
+/persona preview <имя> <тема> — показать промпт и пример текста
+/persona use <имя> [ID канала] — выбрать персону для канала (по умолчанию — вашего)
+
+Поля:
+тон: дерзкий
+длина: 800
+система: системная инструкция
+шаблон: текст запроса
+картинки: 2 (от 0 до 10)
+стиль: общий стиль картинок по-английски, например «film photo, warm light»
+формат: текст или json — в json модель сама предлагает хэштеги, картинки и время публикации
+
+Переменные в шаблоне и системной инструкции: ` + "{topic}, {length}, {tone}, {date}"
+
+// personaFields — названия полей в тексте команды.
+var personaFields = map[string]bool{"тон": true, "длина": true, "система": true, "шаблон": true, "картинки": true, "стиль": true, "формат": true}
+
+// splitWord отделяет первое слово от остального текста.
+func splitWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+// parsePersonaFields разбирает строки вида «поле: значение». Значение может
+// продолжаться на следующих строках до следующего поля.
+func parsePersonaFields(text string) (map[string]string, error) {
+	fields := make(map[string]string)
+	current := ""
+	for _, line := range strings.Split(text, "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok && personaFields[strings.ToLower(strings.TrimSpace(key))] {
+			current = strings.ToLower(strings.TrimSpace(key))
+			fields[current] = strings.TrimSpace(value)
+			continue
+		}
+		if current == "" {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("не понял строку «%s»: ожидается тон:, длина:, система:, шаблон:, картинки:, стиль: или формат:", strings.TrimSpace(line))
+		}
+		fields[current] = strings.TrimSpace(fields[current] + "\n" + line)
+	}
+	return fields, nil
+}
+
+// applyPersonaFields переносит разобранные поля в персону.
+func applyPersonaFields(p *domain.Persona, fields map[string]string) error {
+	if v, ok := fields["длина"]; ok {
+		length, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("длина должна быть числом, а не «%s»", v)
+		}
+		p.Length = length
+	}
+	if v, ok := fields["картинки"]; ok {
+		count, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("число картинок должно быть числом, а не «%s»", v)
+		}
+		p.ImageCount = count
+	}
+	if v, ok := fields["стиль"]; ok {
+		p.ImageStyle = v
+	}
+	if v, ok := fields["формат"]; ok {
+		switch strings.ToLower(v) {
+		case "json":
+			p.Structured = true
+		case "текст":
+			p.Structured = false
+		default:
+			return fmt.Errorf("формат должен быть «текст» или «json», а не «%s»", v)
+		}
+	}
+	if v, ok := fields["тон"]; ok {
+		p.Tone = v
+	}
+	if v, ok := fields["система"]; ok {
+		p.SystemPrompt = v
+	}
+	if v, ok := fields["шаблон"]; ok {
+		p.Template = v
+	}
+	return nil
+}
+
+// personaFormat называет формат ответа модели так, как он пишется в поле «формат».
+func personaFormat(p domain.Persona) string {
+	if p.Structured {
+		return "json"
+	}
+	return "текст"
+}
+
+// handlePersona обрабатывает команду /persona.
+func (h *Handler) handlePersona(chatID int64, args string) {
+	sub, rest := splitWord(args)
+	switch sub {
+	case "", "list":
+		h.sendPersonas(chatID)
+	case "show":
+		h.showPersona(chatID, rest)
+	case "new", "edit":
+		h.savePersona(chatID, sub == "new", rest)
+	case "preview":
+		h.previewPersona(chatID, rest)
+	case "use":
+		h.usePersona(chatID, rest)
+	default:
+		h.api.Send(tgbotapi.NewMessage(chatID, personaHelp))
+	}
+}
+
+// sendPersonas показывает персоны и каналы, где они выбраны.
+func (h *Handler) sendPersonas(chatID int64) {
+	personas, err := h.personaUsecase.List()
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при получении персон"))
+		log.Printf("Ошибка получения персон: %v", err)
+		return
+	}
+	byChannel, err := h.personaUsecase.ChannelPersonas()
+	if err != nil {
+		log.Printf("Ошибка получения персон каналов: %v", err)
+	}
+	channels, err := h.channelUsecase.List()
+	if err != nil {
+		log.Printf("Ошибка получения каналов: %v", err)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Персоны:\n\n")
+	for i, p := range personas {
+		builder.WriteString(fmt.Sprintf("• %s — тон: %s, %d симв., картинок: %d, формат: %s", p.Name, p.Tone, p.Length, p.ImageCount, personaFormat(p)))
+		var used []string
+		for _, ch := range channels {
+			personaID, ok := byChannel[ch.ID]
+			// Каналы без выбранной персоны пишут первой
+			if (ok && personaID == p.ID) || (!ok && i == 0) {
+				used = append(used, ch.Title)
+			}
+		}
+		if len(used) > 0 {
+			builder.WriteString(" — " + strings.Join(used, ", "))
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n" + personaHelp)
+	h.api.Send(tgbotapi.NewMessage(chatID, builder.String()))
+}
+
+func (h *Handler) showPersona(chatID int64, name string) {
+	p, err := h.personaUsecase.Find(name)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+	h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Персона %s\n\nтон: %s\nдлина: %d\nсистема: %s\nшаблон: %s\nкартинки: %d\nстиль: %s\nформат: %s",
+		p.Name, p.Tone, p.Length, p.SystemPrompt, p.Template, p.ImageCount, p.ImageStyle, personaFormat(p))))
+}
+
+// savePersona создает персону или меняет указанные поля существующей.
+func (h *Handler) savePersona(chatID int64, create bool, args string) {
+	name, body := splitWord(args)
+	if name == "" {
+		h.api.Send(tgbotapi.NewMessage(chatID, personaHelp))
+		return
+	}
+	fields, err := parsePersonaFields(body)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+
+	var p domain.Persona
+	if create {
+		p.Name = name
+		p.ImageCount = usecase.DefaultImageCount
+	} else if p, err = h.personaUsecase.Find(name); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+	if err := applyPersonaFields(&p, fields); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+
+	if create {
+		p, err = h.personaUsecase.Create(chatID, p)
+	} else {
+		err = h.personaUsecase.Update(p)
+	}
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Персона не сохранена: %v", err)))
+		return
+	}
+	h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Персона %s сохранена. Проверить: /persona preview %s <тема>", p.Name, p.Name)))
+}
+
+// previewPersona показывает итоговый промпт и пример текста без сохранения черновика.
+func (h *Handler) previewPersona(chatID int64, args string) {
+	name, topic := splitWord(args)
+	if topic == "" {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Укажи персону и тему: /persona preview <имя> <тема>"))
+		return
+	}
+	p, err := h.personaUsecase.Find(name)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+
+	system, prompt := p.Render(topic, time.Now().In(h.userUsecase.Location(chatID)))
+	h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Система:\n%s\n\nЗапрос:\n%s", system, prompt)))
+	h.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+
+	loc := h.userUsecase.Location(chatID)
+	post, err := h.generateUsecase.For(chatID, 0).WritePost(p, topic, loc, nil)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка генерации: %v", err)))
+		return
+	}
+	text := "Пример:\n\n" + post.Text
+	if !post.PublishAt.IsZero() {
+		text += "\n\nВремя публикации: " + post.PublishAt.In(loc).Format("15:04")
+	}
+	if len(post.ImagePrompts) > 0 {
+		text += "\n\nКартинки:\n- " + strings.Join(post.ImagePrompts, "\n- ")
+	}
+	h.api.Send(tgbotapi.NewMessage(chatID, text))
+}
+
+// usePersona выбирает персону для канала; без ID — для канала редактора по умолчанию.
+func (h *Handler) usePersona(chatID int64, args string) {
+	name, channelArg := splitWord(args)
+	if name == "" {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Укажи персону: /persona use <имя> [ID канала]"))
+		return
+	}
+
+	var channelID int64
+	var err error
+	if channelArg != "" {
+		channelID, err = strconv.ParseInt(channelArg, 10, 64)
+		if err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, "ID канала должен быть числом, см. /channels"))
+			return
+		}
+		if _, err := h.channelUsecase.Get(channelID); err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+			return
+		}
+	} else if channelID, err = h.channelUsecase.DefaultChannel(chatID); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+
+	p, err := h.personaUsecase.Use(channelID, name)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Не удалось выбрать персону: %v", err)))
+		return
+	}
+	title := h.channelTitles()[channelID]
+	h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Канал «%s» теперь пишет голосом персоны %s", title, p.Name)))
+}
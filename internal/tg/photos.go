@@ -0,0 +1,174 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// albumDelay — сколько ждать остальные фото альбома, прежде чем ответить один раз.
+const albumDelay = 2 * time.Second
+
+const photoHelp = "Пришлите фото или альбом, чтобы добавить картинки. С подписью «заменить» фото заменят все картинки, «заменить 2» — только вторую."
+
+// parsePhotoCaption разбирает подпись к фото: «заменить» — заменить все картинки,
+// «заменить N» — только N-ю (index с нуля). Без подписи фото добавляется.
+func parsePhotoCaption(caption string) (replace bool, index int, err error) {
+	word, rest := splitWord(strings.ToLower(caption))
+	if word != "заменить" {
+		return false, -1, nil
+	}
+	if rest == "" {
+		return true, -1, nil
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 {
+		return false, -1, fmt.Errorf("не понял, какую картинку заменить: «%s»", rest)
+	}
+	return false, n - 1, nil
+}
+
+// HandlePhoto добавляет присланное или пересланное фото в последний черновик.
+// Фото альбома приходят отдельными сообщениями, поэтому ответ на альбом
+// отправляется один раз, когда придет последнее фото.
+func (h *Handler) HandlePhoto(update tgbotapi.Update) {
+	msg := update.Message
+	chatID := msg.Chat.ID
+	post, err := h.usecase.LatestDraft(chatID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Нет черновика для фото. Сначала сгенерируйте пост."))
+		return
+	}
+	replace, index, err := parsePhotoCaption(msg.Caption)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+
+	photo := msg.Photo[len(msg.Photo)-1]
+	url, err := h.api.GetFileDirectURL(photo.FileID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Не удалось получить фото"))
+		log.Printf("Ошибка получения фото %s: %v", photo.FileID, err)
+		return
+	}
+	img, err := h.mediaUsecase.Import(url, photo.FileID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Фото не сохранено: %v", err)))
+		log.Printf("Ошибка сохранения фото для поста %d: %v", post.ID, err)
+		return
+	}
+
+	if index >= 0 {
+		post, err = h.usecase.ReplaceImage(post.ID, index, img)
+	} else {
+		post, err = h.usecase.AddImages(post.ID, []domain.PostImage{img}, replace)
+	}
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Картинки не изменены: %v", err)))
+		return
+	}
+	log.Printf("Фото добавлено в пост %d, картинок: %d", post.ID, len(post.Images))
+
+	if msg.MediaGroupID == "" {
+		h.sendImageManager(chatID, post)
+		return
+	}
+	h.afterAlbum(chatID, post.ID, msg.MediaGroupID)
+}
+
+// afterAlbum откладывает ответ на альбом, пока приходят его фото.
+func (h *Handler) afterAlbum(chatID, postID int64, groupID string) {
+	h.albumMu.Lock()
+	defer h.albumMu.Unlock()
+	if timer, ok := h.albums[groupID]; ok {
+		timer.Reset(albumDelay)
+		return
+	}
+	h.albums[groupID] = time.AfterFunc(albumDelay, func() {
+		h.albumMu.Lock()
+		delete(h.albums, groupID)
+		h.albumMu.Unlock()
+		if post, err := h.usecase.GetPost(chatID, postID); err == nil {
+			h.sendImageManager(chatID, post)
+		}
+	})
+}
+
+// renderImageManager собирает список картинок поста с кнопками перестановки и удаления.
+func renderImageManager(post domain.Post) (string, tgbotapi.InlineKeyboardMarkup) {
+	text := fmt.Sprintf("Картинки поста #%d: %d из %d.\n\n%s", post.ID, len(post.Images), domain.MaxPostImages, photoHelp)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := range post.Images {
+		row := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🖼 %d", i+1), fmt.Sprintf("img_show:%d:%d", post.ID, i)),
+		)
+		if i > 0 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("⬆️", fmt.Sprintf("img_up:%d:%d", post.ID, i)))
+		}
+		if i < len(post.Images)-1 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("⬇️", fmt.Sprintf("img_down:%d:%d", post.ID, i)))
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("🗑", fmt.Sprintf("img_del:%d:%d", post.ID, i)))
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("👁 Показать пост", fmt.Sprintf("q_open:%d", post.ID)),
+	))
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// sendImageManager показывает список картинок поста.
+func (h *Handler) sendImageManager(chatID int64, post domain.Post) {
+	text, markup := renderImageManager(post)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = markup
+	if _, err := h.api.Send(msg); err != nil {
+		log.Printf("Ошибка отправки списка картинок поста %d: %v", post.ID, err)
+	}
+}
+
+// handleImageCallback обрабатывает кнопки списка картинок: показ, перестановку и удаление.
+func (h *Handler) handleImageCallback(update tgbotapi.Update, post domain.Post, action, arg string) {
+	callbackID := update.CallbackQuery.ID
+	chatID := update.CallbackQuery.Message.Chat.ID
+	messageID := update.CallbackQuery.Message.MessageID
+
+	if action == "images" {
+		h.api.Request(tgbotapi.NewCallback(callbackID, ""))
+		h.sendImageManager(chatID, post)
+		return
+	}
+	index, err := strconv.Atoi(arg)
+	if err != nil || index < 0 || index >= len(post.Images) {
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Картинка не найдена"))
+		return
+	}
+
+	switch action {
+	case "img_show":
+		h.api.Request(tgbotapi.NewCallback(callbackID, ""))
+		if _, err := h.sendPhoto(chatID, post.Images[index], fmt.Sprintf("Картинка %d", index+1)); err != nil {
+			log.Printf("Ошибка отправки картинки %d поста %d: %v", index+1, post.ID, err)
+		}
+		return
+	case "img_up":
+		post, err = h.usecase.MoveImage(post.ID, index, index-1)
+	case "img_down":
+		post, err = h.usecase.MoveImage(post.ID, index, index+1)
+	case "img_del":
+		post, err = h.usecase.RemoveImage(post.ID, index)
+	}
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(callbackID, err.Error()))
+		return
+	}
+	h.api.Request(tgbotapi.NewCallback(callbackID, "Готово"))
+	text, markup := renderImageManager(post)
+	h.api.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, markup))
+}
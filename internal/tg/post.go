@@ -0,0 +1,210 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/textlimit"
+	"lady/internal/timeparse"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// draftKeyboard возвращает кнопки действий над черновиком, переключатель вариантов
+// текста, AI-правку и вход в список картинок.
+func draftKeyboard(post domain.Post, variants []domain.PostVariant) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Опубликовать", fmt.Sprintf("publish:%d", post.ID)),
+		tgbotapi.NewInlineKeyboardButtonData("Редактировать", fmt.Sprintf("edit:%d", post.ID)),
+		tgbotapi.NewInlineKeyboardButtonData("Запланировать", fmt.Sprintf("schedule:%d", post.ID)),
+	)}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if len(variants) > 1 {
+		current := len(variants) - 1
+		for i, v := range variants {
+			if v.ID == post.VariantID {
+				current = i
+			}
+		}
+		// Переключение по кругу: с первого варианта ◀️ ведет на последний
+		prev := variants[(current+len(variants)-1)%len(variants)]
+		next := variants[(current+1)%len(variants)]
+		nav = append(nav,
+			tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("variant:%d:%d", post.ID, prev.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", current+1, len(variants)), fmt.Sprintf("variant:%d:%d", post.ID, post.VariantID)),
+			tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("variant:%d:%d", post.ID, next.ID)),
+		)
+	}
+	if post.TopicID != 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("🔄 Перегенерировать", fmt.Sprintf("regen:%d", post.ID)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🪄 AI-правка", fmt.Sprintf("ai_edit:%d", post.ID)),
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🖼 Картинки (%d)", len(post.Images)), fmt.Sprintf("images:%d", post.ID)),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// postKeyboard возвращает кнопки черновика с его вариантами текста.
+func (h *Handler) postKeyboard(post domain.Post) tgbotapi.InlineKeyboardMarkup {
+	variants, err := h.usecase.ListVariants(post.ID)
+	if err != nil {
+		log.Printf("Ошибка получения вариантов поста %d: %v", post.ID, err)
+	}
+	return draftKeyboard(post, variants)
+}
+
+// regeneratePost генерирует новый вариант текста по теме поста и показывает его
+// в том же сообщении.
+func (h *Handler) regeneratePost(chatID int64, messageID int, post domain.Post) {
+	topic, err := h.usecase.PostTopic(post)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Не получится перегенерировать: %v", err)))
+		return
+	}
+	h.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+
+	channelID := post.ChannelID
+	if channelID == 0 {
+		channelID, _ = h.channelUsecase.DefaultChannel(chatID)
+	}
+	written, err := h.writePost(h.generator(chatID, channelID, post.SeriesID).Fresh(), chatID, channelID, topic.Title, nil)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка генерации: %v", err)))
+		return
+	}
+	post, err = h.usecase.AddVariant(post.ID, written.Text)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Вариант не сохранен: %v", err)))
+		return
+	}
+	h.showPostText(chatID, messageID, post)
+}
+
+// selectVariant переключает пост на другой вариант текста.
+func (h *Handler) selectVariant(callbackID string, chatID int64, messageID int, post domain.Post, variantID int64) {
+	if variantID == post.VariantID {
+		h.api.Request(tgbotapi.NewCallback(callbackID, "Этот вариант уже выбран"))
+		return
+	}
+	post, err := h.usecase.SelectVariant(post.ID, variantID)
+	if err != nil {
+		h.api.Request(tgbotapi.NewCallback(callbackID, err.Error()))
+		return
+	}
+	h.api.Request(tgbotapi.NewCallback(callbackID, ""))
+	h.showPostText(chatID, messageID, post)
+}
+
+// showPostText заменяет текст и кнопки сообщения с черновиком; если сообщение
+// уже не изменить, отправляет пост заново.
+func (h *Handler) showPostText(chatID int64, messageID int, post domain.Post) {
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, post.Text, h.postKeyboard(post))
+	if _, err := h.api.Request(edit); err != nil {
+		log.Printf("Ошибка обновления поста %d в чате %d: %v", post.ID, chatID, err)
+		h.sendPost(chatID, post)
+	}
+}
+
+// sendPost показывает черновик: текст с кнопками и картинки.
+func (h *Handler) sendPost(chatID int64, post domain.Post) {
+	msg := tgbotapi.NewMessage(chatID, post.Text)
+	msg.ReplyMarkup = h.postKeyboard(post)
+	if _, err := h.api.Send(msg); err != nil {
+		log.Printf("Ошибка отправки сообщения: %v", err)
+	}
+	h.sendPostImages(chatID, post)
+}
+
+// sendPostImages показывает картинки черновика, у каждой свои кнопки.
+func (h *Handler) sendPostImages(chatID int64, post domain.Post) {
+	for _, img := range post.Images {
+		if err := h.sendImagePreview(chatID, post.ID, img); err != nil {
+			log.Printf("Ошибка отправки картинки поста %d: %v", post.ID, err)
+		}
+	}
+}
+
+// warnLongText предупреждает, что текст не поместится в подпись, и предлагает его сократить.
+func (h *Handler) warnLongText(chatID, postID int64, text string) {
+	length := textlimit.Len(text)
+	if length <= textlimit.Caption {
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+		"Текст длиннее подписи к фото: %d из %d символов. При публикации продолжение уйдет ответным сообщением.", length, textlimit.Caption))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✂️ Сократить", fmt.Sprintf("shorten:%d", postID)),
+	))
+	h.api.Send(msg)
+}
+
+// shortenPost сокращает текст поста моделью до предела подписи и показывает пост заново.
+func (h *Handler) shortenPost(chatID int64, post domain.Post) {
+	h.api.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+	text, err := h.generateUsecase.For(chatID, post.ChannelID).Condense(post.Text, textlimit.Caption)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Не получилось сократить: %v", err)))
+		return
+	}
+	if err := h.usecase.UpdatePostText(post.ID, text); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка обновления текста: %v", err)))
+		return
+	}
+	post.Text = text
+	h.sendPost(chatID, post)
+}
+
+// scheduleHint — подсказка с примерами времени публикации.
+const scheduleHint = "Например: «завтра в 18:00», «через 2 часа», «в пятницу утром», «сегодня 21:30» или 11.08.2025 17:30"
+
+// schedulePost разбирает введенное время и ставит пост в очередь. Если время
+// пришлось додумать, сначала просит подтверждение.
+func (h *Handler) schedulePost(chatID, postID int64, input string) {
+	log.Printf("Попытка запланировать пост %d для chatID %d с временем: %s", postID, chatID, input)
+	loc := h.userUsecase.Location(chatID)
+	res, err := timeparse.Parse(input, time.Now().In(loc))
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Не понял время: %v.\n%s", err, scheduleHint))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(cancelScheduleButton(postID)))
+		h.api.Send(msg)
+		log.Printf("Ошибка парсинга времени '%s': %v", input, err)
+		return
+	}
+	if res.Ambiguous {
+		h.confirmSchedule(chatID, postID, res)
+		return
+	}
+	h.saveSchedule(chatID, postID, res.Time)
+}
+
+// confirmSchedule показывает, как было понято время, с кнопками подтверждения.
+func (h *Handler) confirmSchedule(chatID, postID int64, res timeparse.Result) {
+	text := fmt.Sprintf("Запланировать на %s (%s)?", timeparse.Format(res.Time), res.Time.Location())
+	if res.Reason != "" {
+		text += "\n\n" + res.Reason
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Да", fmt.Sprintf("sched_ok:%d:%d", postID, res.Time.Unix())),
+		tgbotapi.NewInlineKeyboardButtonData("✏️ Другое время", fmt.Sprintf("sched_other:%d", postID)),
+	))
+	h.api.Send(msg)
+}
+
+// saveSchedule ставит пост в очередь на указанное время.
+func (h *Handler) saveSchedule(chatID, postID int64, publishAt time.Time) {
+	if err := h.usecase.SchedulePost(postID, publishAt); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Ошибка при планировании поста: %v", err)))
+		log.Printf("Ошибка планирования поста %d: %v", postID, err)
+		return
+	}
+	h.usecase.ClearPendingSchedule(chatID)
+	h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Пост с фотографиями запланирован на %s (%s). Очередь: /queue", timeparse.Format(publishAt), publishAt.Location())))
+	log.Printf("Пост %d для chatID %d запланирован на %s UTC", postID, chatID, publishAt.UTC().Format("02.01.2006 15:04"))
+}
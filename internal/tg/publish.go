@@ -0,0 +1,144 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/textlimit"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// publishToChannel отправляет пост в его канал: картинки одной медиа-группой
+// с текстом в подписи, одну картинку — фото с подписью, пост без картинок —
+// обычным сообщением. Текст, не поместившийся в подпись, уходит ответом;
+// возвращает true, если так случилось.
+func (h *Handler) publishToChannel(post domain.Post) (bool, error) {
+	target := post.ChannelID
+	if target == 0 {
+		defaultChannel, err := h.channelUsecase.DefaultChannel(post.AuthorID)
+		if err != nil {
+			return false, err
+		}
+		target = defaultChannel
+	}
+	if len(post.Images) > domain.MaxPostImages {
+		return false, fmt.Errorf("в посте %d картинок, в медиа-группу помещается %d", len(post.Images), domain.MaxPostImages)
+	}
+	if len(post.Images) == 0 {
+		return false, h.publishText(target, post)
+	}
+
+	caption, rest := textlimit.Cut(post.Text, textlimit.Caption)
+	log.Printf("Длина подписи поста %d: %d символов, остаток: %d", post.ID, textlimit.Len(caption), textlimit.Len(rest))
+
+	var replyTo int
+	if len(post.Images) == 1 {
+		message, err := h.sendPhoto(target, post.Images[0], caption)
+		if err != nil {
+			return false, err
+		}
+		replyTo = message.MessageID
+	} else {
+		files := make([]interface{}, len(post.Images))
+		for i, img := range post.Images {
+			media := tgbotapi.NewInputMediaPhoto(photoData(img))
+			if i == 0 {
+				media.Caption = caption
+			}
+			files[i] = media
+		}
+		messages, err := h.api.SendMediaGroup(tgbotapi.NewMediaGroup(target, files))
+		if err != nil {
+			return false, err
+		}
+		h.rememberFileIDs(post.Images, messages)
+		if len(messages) > 0 {
+			replyTo = messages[0].MessageID
+		}
+	}
+	if rest == "" {
+		return false, nil
+	}
+
+	// Картинки уже в канале: ошибку продолжения не считаем ошибкой публикации,
+	// иначе повтор опубликует их второй раз
+	for _, part := range textlimit.Split(rest, textlimit.Message) {
+		msg := tgbotapi.NewMessage(target, part)
+		msg.ReplyToMessageID = replyTo
+		if _, err := h.api.Send(msg); err != nil {
+			log.Printf("Ошибка отправки продолжения поста %d: %v", post.ID, err)
+			break
+		}
+	}
+	return true, nil
+}
+
+// publishText публикует пост без картинок; длинный текст делится на сообщения.
+func (h *Handler) publishText(target int64, post domain.Post) error {
+	for i, part := range textlimit.Split(post.Text, textlimit.Message) {
+		if _, err := h.api.Send(tgbotapi.NewMessage(target, part)); err != nil {
+			if i == 0 {
+				return err
+			}
+			log.Printf("Ошибка отправки продолжения поста %d: %v", post.ID, err)
+			break
+		}
+	}
+	return nil
+}
+
+// publishNow публикует пост в канал по команде редактора, если его пропустила модерация.
+func (h *Handler) publishNow(chatID int64, post domain.Post) {
+	if verdict := h.moderationUsecase.Check(post); verdict.Action != domain.ModerationAllow {
+		log.Printf("Пост %d не прошел модерацию: %s", post.ID, verdict.Summary())
+		h.sendModeration(chatID, post, verdict)
+		return
+	}
+	h.publishApproved(chatID, post)
+}
+
+// publishApproved публикует пост в канал без проверки модерацией.
+func (h *Handler) publishApproved(chatID int64, post domain.Post) {
+	if err := h.usecase.StartPublishing(post.ID); err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Пост нельзя опубликовать: %v", err)))
+		log.Printf("Ошибка перевода поста %d в публикацию: %v", post.ID, err)
+		return
+	}
+
+	split, err := h.publishToChannel(post)
+	if err != nil {
+		log.Printf("Ошибка публикации поста %d: %v", post.ID, err)
+		if err := h.usecase.MarkFailed(post.ID, err); err != nil {
+			log.Printf("Ошибка смены статуса поста %d: %v", post.ID, err)
+		}
+		h.sendFailure(chatID, post.ID, err)
+		return
+	}
+	if err := h.usecase.MarkPublished(post.ID); err != nil {
+		log.Printf("Ошибка смены статуса поста %d: %v", post.ID, err)
+	}
+
+	notifyMsg := tgbotapi.NewMessage(chatID, "Пост с фотографиями успешно опубликован в канале!")
+	if split {
+		notifyMsg.Text += "\nТекст не поместился в подпись — продолжение опубликовано ответом."
+	}
+	h.api.Send(notifyMsg)
+}
+
+// failureKeyboard возвращает кнопки для поста, который не удалось опубликовать.
+func failureKeyboard(postID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔁 Повторить", fmt.Sprintf("retry:%d", postID)),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", fmt.Sprintf("cancel:%d", postID)),
+	))
+}
+
+// sendFailure сообщает автору, что пост не удалось опубликовать, и предлагает повторить или отменить.
+func (h *Handler) sendFailure(chatID, postID int64, cause error) {
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Не удалось опубликовать пост #%d: %v", postID, cause))
+	msg.ReplyMarkup = failureKeyboard(postID)
+	if _, err := h.api.Send(msg); err != nil {
+		log.Printf("Ошибка отправки уведомления об ошибке публикации chatID %d: %v", chatID, err)
+	}
+}
@@ -0,0 +1,99 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// queuePreviewLen — сколько символов текста показывать в списке очереди.
+const queuePreviewLen = 60
+
+// renderQueue собирает текст и кнопки для списка запланированных постов.
+func renderQueue(posts []domain.Post, channelTitles map[int64]string, loc *time.Location) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if len(posts) == 0 {
+		return "Очередь публикации пуста", nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Очередь публикации (%s):\n\n", loc))
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, p := range posts {
+		when := p.PublishAt.In(loc).Format("02.01.2006 15:04")
+		if p.Attempts > 0 && !p.NextAttemptAt.IsZero() {
+			when += fmt.Sprintf(" (повтор %d в %s)", p.Attempts+1, p.NextAttemptAt.In(loc).Format("15:04"))
+		}
+		if title, ok := channelTitles[p.ChannelID]; ok {
+			when += " · " + title
+		}
+		builder.WriteString(fmt.Sprintf("%d. %s — %s\n", i+1, when, preview(p.Text, queuePreviewLen)))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("📄 %d", i+1), fmt.Sprintf("q_open:%d", p.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🕒", fmt.Sprintf("q_resched:%d", p.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌", fmt.Sprintf("q_cancel:%d", p.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🚀", fmt.Sprintf("q_pub:%d", p.ID)),
+		))
+	}
+	builder.WriteString("\n📄 открыть · 🕒 перенести · ❌ отменить · 🚀 опубликовать сейчас")
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return builder.String(), &markup
+}
+
+// preview возвращает первые n символов текста в одну строку.
+func preview(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
+
+// sendQueue отправляет список запланированных постов чата.
+func (h *Handler) sendQueue(chatID int64) {
+	posts, err := h.usecase.ListQueue(chatID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при получении очереди"))
+		log.Printf("Ошибка получения очереди для chatID %d: %v", chatID, err)
+		return
+	}
+	text, markup := renderQueue(posts, h.channelTitles(), h.userUsecase.Location(chatID))
+	msg := tgbotapi.NewMessage(chatID, text)
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	h.api.Send(msg)
+}
+
+// refreshQueue перерисовывает сообщение со списком очереди после изменений.
+func (h *Handler) refreshQueue(chatID int64, messageID int) {
+	posts, err := h.usecase.ListQueue(chatID)
+	if err != nil {
+		log.Printf("Ошибка получения очереди для chatID %d: %v", chatID, err)
+		return
+	}
+	text, markup := renderQueue(posts, h.channelTitles(), h.userUsecase.Location(chatID))
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ReplyMarkup = markup
+	if _, err := h.api.Request(edit); err != nil {
+		log.Printf("Ошибка обновления очереди для chatID %d: %v", chatID, err)
+	}
+}
+
+// channelTitles возвращает названия привязанных каналов по их ID.
+func (h *Handler) channelTitles() map[int64]string {
+	titles := make(map[int64]string)
+	channels, err := h.channelUsecase.List()
+	if err != nil {
+		log.Printf("Ошибка получения каналов: %v", err)
+		return titles
+	}
+	for _, ch := range channels {
+		titles[ch.ID] = ch.Title
+	}
+	return titles
+}
@@ -0,0 +1,79 @@
+package tg
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const seriesHelp = `Серии — посты, которые продолжают друг друга. Пока вы пишете серию, каждый новый пост получает пересказ последних опубликованных постов серии. Команды:
+/series — список серий
+/series new <название> — начать серию
+/series use <ID> — продолжить серию
+/series off — писать вне серий`
+
+// handleSeries обрабатывает команду /series.
+func (h *Handler) handleSeries(chatID int64, args string) {
+	sub, rest := splitWord(args)
+	switch sub {
+	case "", "list":
+		h.sendSeries(chatID)
+	case "new":
+		series, err := h.seriesUsecase.Create(chatID, rest)
+		if err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Серия не создана: %v", err)))
+			return
+		}
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Серия «%s» (ID %d) начата. Новые посты будут ее продолжать, выйти: /series off", series.Title, series.ID)))
+	case "use":
+		id, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, "Укажи ID серии: /series use <ID>"))
+			return
+		}
+		series, err := h.seriesUsecase.Use(chatID, id)
+		if err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, err.Error()))
+			return
+		}
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Продолжаем серию «%s»: опубликовано постов — %d", series.Title, series.Posts)))
+	case "off":
+		if err := h.seriesUsecase.Stop(chatID); err != nil {
+			h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при выходе из серии"))
+			return
+		}
+		h.api.Send(tgbotapi.NewMessage(chatID, "Новые посты пишутся вне серий"))
+	default:
+		h.api.Send(tgbotapi.NewMessage(chatID, seriesHelp))
+	}
+}
+
+// sendSeries показывает серии и отмечает ту, которую пишет редактор.
+func (h *Handler) sendSeries(chatID int64) {
+	list, err := h.seriesUsecase.List()
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при получении серий"))
+		log.Printf("Ошибка получения серий: %v", err)
+		return
+	}
+	if len(list) == 0 {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Серий пока нет.\n\n"+seriesHelp))
+		return
+	}
+
+	active := h.seriesUsecase.Active(chatID)
+	var builder strings.Builder
+	builder.WriteString("Серии:\n\n")
+	for _, s := range list {
+		mark := "•"
+		if s.ID == active {
+			mark = "▶️"
+		}
+		builder.WriteString(fmt.Sprintf("%s %d. %s — опубликовано постов: %d\n", mark, s.ID, s.Title, s.Posts))
+	}
+	builder.WriteString("\n" + seriesHelp)
+	h.api.Send(tgbotapi.NewMessage(chatID, builder.String()))
+}
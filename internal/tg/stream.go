@@ -0,0 +1,112 @@
+package tg
+
+import (
+	"fmt"
+	"lady/internal/textlimit"
+	"lady/internal/timeparse"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// streamEditInterval — как часто обновлять сообщение во время генерации:
+// Telegram разрешает примерно одну правку в секунду на чат.
+const streamEditInterval = 1500 * time.Millisecond
+
+// liveMessage — сообщение-заглушка, в котором текст появляется по мере генерации.
+type liveMessage struct {
+	api       *tgbotapi.BotAPI
+	chatID    int64
+	messageID int
+	lastEdit  time.Time
+	lastText  string
+}
+
+// newLiveMessage отправляет заглушку, которую затем будут править.
+func (h *Handler) newLiveMessage(chatID int64, placeholder string) (*liveMessage, error) {
+	msg, err := h.api.Send(tgbotapi.NewMessage(chatID, placeholder))
+	if err != nil {
+		return nil, err
+	}
+	return &liveMessage{api: h.api, chatID: chatID, messageID: msg.MessageID, lastEdit: time.Now(), lastText: placeholder}, nil
+}
+
+// Update показывает накопленный текст, но не чаще streamEditInterval.
+func (m *liveMessage) Update(text string) {
+	if time.Since(m.lastEdit) < streamEditInterval {
+		return
+	}
+	m.edit(text+" ▍", nil)
+}
+
+// Set показывает окончательный текст с кнопками без ограничения частоты.
+func (m *liveMessage) Set(text string, markup *tgbotapi.InlineKeyboardMarkup) error {
+	return m.edit(text, markup)
+}
+
+func (m *liveMessage) edit(text string, markup *tgbotapi.InlineKeyboardMarkup) error {
+	text, _ = textlimit.Cut(strings.TrimSpace(text), textlimit.Message)
+	if text == "" || (text == m.lastText && markup == nil) {
+		return nil
+	}
+	edit := tgbotapi.NewEditMessageText(m.chatID, m.messageID, text)
+	edit.ReplyMarkup = markup
+	m.lastEdit = time.Now()
+	if _, err := m.api.Request(edit); err != nil {
+		log.Printf("Ошибка обновления сообщения %d в чате %d: %v", m.messageID, m.chatID, err)
+		return err
+	}
+	m.lastText = text
+	return nil
+}
+
+// generateDraftLive генерирует пост по теме, показывая текст в одном сообщении
+// по мере ответа модели, сохраняет черновик и прикрепляет к тексту кнопки.
+// Если редактор пишет серию, пост продолжает ее.
+func (h *Handler) generateDraftLive(chatID, topicID int64, topic, placeholder string) {
+	h.usecase.ClearPendingInput(chatID)
+	live, err := h.newLiveMessage(chatID, placeholder)
+	if err != nil {
+		log.Printf("Ошибка отправки заглушки в чат %d: %v", chatID, err)
+		return
+	}
+
+	channelID, _ := h.channelUsecase.DefaultChannel(chatID)
+	seriesID := h.seriesUsecase.Active(chatID)
+	written, err := h.writePost(h.generator(chatID, channelID, seriesID), chatID, channelID, topic, live.Update)
+	if err != nil {
+		live.Set(fmt.Sprintf("Ошибка генерации: %v", err), nil)
+		return
+	}
+	text := written.Text
+	live.Set(text+"\n\n🎨 Рисую картинки...", nil)
+
+	images, imagesErr := h.generateImages(chatID, channelID, text, written.ImagePrompts)
+	if imagesErr != nil && len(images) == 0 {
+		live.Set(fmt.Sprintf("%s\n\nОшибка генерации картинок: %v", text, imagesErr), nil)
+		return
+	}
+	post, err := h.createDraft(chatID, topicID, seriesID, text, images)
+	if err != nil {
+		live.Set(text, nil)
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка сохранения поста"))
+		log.Printf("Ошибка сохранения черновика для chatID %d: %v", chatID, err)
+		return
+	}
+	log.Printf("Сгенерирован пост %d для chatID %d, картинок: %d", post.ID, chatID, len(post.Images))
+
+	markup := h.postKeyboard(post)
+	if err := live.Set(post.Text, &markup); err != nil {
+		h.sendPost(chatID, post)
+	} else {
+		h.sendPostImages(chatID, post)
+	}
+	if imagesErr != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Получились не все картинки: %v", imagesErr)))
+	}
+	if !written.PublishAt.IsZero() {
+		h.confirmSchedule(chatID, post.ID, timeparse.Result{Time: written.PublishAt, Reason: "Это время предложила модель."})
+	}
+}
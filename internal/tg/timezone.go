@@ -0,0 +1,53 @@
+package tg
+
+import (
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// popularTimeZones — пояса для быстрого выбора кнопками.
+var popularTimeZones = []struct {
+	Title string
+	Name  string
+}{
+	{"Калининград", "Europe/Kaliningrad"},
+	{"Москва", "Europe/Moscow"},
+	{"Екатеринбург", "Asia/Yekaterinburg"},
+	{"Алматы", "Asia/Almaty"},
+	{"Новосибирск", "Asia/Novosibirsk"},
+	{"Владивосток", "Asia/Vladivostok"},
+}
+
+// setTimeZone показывает текущий часовой пояс редактора или сохраняет новый.
+func (h *Handler) setTimeZone(chatID int64, args string) {
+	if args == "" {
+		loc := h.userUsecase.Location(chatID)
+		var rows [][]tgbotapi.InlineKeyboardButton
+		var row []tgbotapi.InlineKeyboardButton
+		for _, tz := range popularTimeZones {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(tz.Title, "tz:"+tz.Name))
+			if len(row) == 3 {
+				rows = append(rows, row)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"Ваш часовой пояс: %s (сейчас %s).\nВыберите другой или отправьте /timezone <пояс>, например /timezone Europe/Moscow, /timezone Алматы или /timezone UTC+5",
+			loc, time.Now().In(loc).Format("15:04")))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		h.api.Send(msg)
+		return
+	}
+
+	loc, err := h.userUsecase.SetTimeZone(chatID, args)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Не удалось сменить часовой пояс: %v", err)))
+		return
+	}
+	h.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Часовой пояс: %s, сейчас %s. Даты публикации теперь вводятся и показываются в нем.", loc, time.Now().In(loc).Format("02.01.2006 15:04"))))
+}
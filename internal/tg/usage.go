@@ -0,0 +1,80 @@
+package tg
+
+import (
+	"fmt"
+	"lady/config"
+	"lady/internal/domain"
+	"lady/internal/usecase"
+	"log"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// renderUsage собирает отчет о расходе; квоты показываются рядом с расходом,
+// если они заданы. Раздел по каналам есть только в отчете администратора.
+func renderUsage(r usecase.UsageReport, limits config.UsageConfig, admin bool, channelTitles map[int64]string) string {
+	var builder strings.Builder
+	builder.WriteString("Ваш расход на генерацию (UTC):\n\n")
+	builder.WriteString("Сегодня: " + formatUsage(r.Day, limits.DailyTokens, limits.DailyImages) + "\n")
+	builder.WriteString("За месяц: " + formatUsage(r.Month, limits.MonthlyTokens, limits.MonthlyImages) + "\n")
+	if !admin {
+		return builder.String()
+	}
+
+	builder.WriteString("\nВесь бот за месяц: " + formatUsage(r.All, 0, 0))
+	if limits.MonthlyBudget > 0 {
+		builder.WriteString(fmt.Sprintf("\nБюджет: $%.2f из $%.2f (%.0f%%)", r.All.Cost, limits.MonthlyBudget, r.All.Cost/limits.MonthlyBudget*100))
+	}
+	if len(r.Channels) == 0 {
+		return builder.String()
+	}
+
+	ids := make([]int64, 0, len(r.Channels))
+	for id := range r.Channels {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return r.Channels[ids[i]].Cost > r.Channels[ids[j]].Cost })
+	builder.WriteString("\n\nПо каналам за месяц:\n")
+	for _, id := range ids {
+		title, ok := channelTitles[id]
+		switch {
+		case id == 0:
+			title = "без канала"
+		case !ok:
+			title = fmt.Sprintf("канал %d", id)
+		}
+		builder.WriteString(fmt.Sprintf("- %s: %s\n", title, formatUsage(r.Channels[id], 0, 0)))
+	}
+	return builder.String()
+}
+
+// formatUsage описывает расход в одну строку; нулевой лимит не показывается.
+func formatUsage(t domain.UsageTotals, tokenLimit, imageLimit int) string {
+	tokens := fmt.Sprintf("%d токенов", t.Tokens)
+	if tokenLimit > 0 {
+		tokens = fmt.Sprintf("%d из %d токенов", t.Tokens, tokenLimit)
+	}
+	images := fmt.Sprintf("%d картинок", t.Images)
+	if imageLimit > 0 {
+		images = fmt.Sprintf("%d из %d картинок", t.Images, imageLimit)
+	}
+	return fmt.Sprintf("%s, %s, $%.2f", tokens, images, t.Cost)
+}
+
+// sendUsage отправляет редактору отчет о расходе.
+func (h *Handler) sendUsage(chatID int64) {
+	report, err := h.usageUsecase.Report(chatID)
+	if err != nil {
+		h.api.Send(tgbotapi.NewMessage(chatID, "Ошибка при подсчете расхода"))
+		log.Printf("Ошибка отчета о расходе для chatID %d: %v", chatID, err)
+		return
+	}
+	admin := h.usageUsecase.IsAdmin(chatID)
+	var titles map[int64]string
+	if admin {
+		titles = h.channelTitles()
+	}
+	h.api.Send(tgbotapi.NewMessage(chatID, renderUsage(report, h.usageUsecase.Limits(), admin, titles)))
+}
@@ -0,0 +1,520 @@
+// Package timeparse разбирает время публикации, которое редактор пишет
+// по-русски: «завтра в 18:00», «через 2 часа», «в пятницу утром»,
+// «сегодня 21:30», «15 августа в 9 вечера», а также ISO-метки и формат
+// DD.MM.YYYY HH:MM.
+package timeparse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result — разобранное время публикации.
+type Result struct {
+	Time time.Time
+	// Ambiguous означает, что часть времени пришлось додумать
+	// (например, «утром» или «в 7» без «утра/вечера»), и его стоит подтвердить.
+	Ambiguous bool
+	Reason    string // почему время неоднозначно
+}
+
+// defaultHour — час публикации, если указан только день.
+const defaultHour = 10
+
+// ErrEmpty возвращается для пустой строки.
+var ErrEmpty = errors.New("не указано время")
+
+// layouts — точные форматы, которые разбираются без эвристик.
+var layouts = []string{
+	"02.01.2006 15:04",
+	"02.01.2006 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+}
+
+var weekdays = []struct {
+	prefix string
+	day    time.Weekday
+}{
+	{"понедельн", time.Monday},
+	{"пн", time.Monday},
+	{"вторн", time.Tuesday},
+	{"вт", time.Tuesday},
+	{"сред", time.Wednesday},
+	{"ср", time.Wednesday},
+	{"четверг", time.Thursday},
+	{"чт", time.Thursday},
+	{"пятниц", time.Friday},
+	{"пт", time.Friday},
+	{"суббот", time.Saturday},
+	{"сб", time.Saturday},
+	{"воскресен", time.Sunday},
+	{"вс", time.Sunday},
+}
+
+var months = []struct {
+	prefix string
+	month  time.Month
+}{
+	{"январ", time.January},
+	{"феврал", time.February},
+	{"март", time.March},
+	{"апрел", time.April},
+	{"мая", time.May},
+	{"май", time.May},
+	{"июн", time.June},
+	{"июл", time.July},
+	{"август", time.August},
+	{"сентябр", time.September},
+	{"октябр", time.October},
+	{"ноябр", time.November},
+	{"декабр", time.December},
+}
+
+// partsOfDay — расплывчатые указания времени и час, который за ними подразумевается.
+var partsOfDay = map[string]int{
+	"утром":   9,
+	"днем":    13,
+	"вечером": 19,
+	"ночью":   23,
+}
+
+var (
+	reClock   = regexp.MustCompile(`^(\d{1,2})[:.](\d{2})$`)
+	reDate    = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{2}|\d{4}))?$`)
+	reISODate = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	reNumber  = regexp.MustCompile(`^\d+(?:[.,]\d+)?$`)
+)
+
+// fillers — служебные слова, которые ничего не меняют.
+var fillers = map[string]bool{
+	"в": true, "во": true, "на": true, "к": true, "около": true, "и": true,
+	"часов": true, "часа": true, "час": true, "ч": true,
+}
+
+// Parse разбирает время публикации относительно now. Время без явного пояса
+// считается в поясе now.Location().
+func Parse(input string, now time.Time) (Result, error) {
+	raw := strings.TrimSpace(input)
+	if raw == "" {
+		return Result{}, ErrEmpty
+	}
+	loc := now.Location()
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return Result{Time: t.In(loc)}, nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return Result{Time: t}, nil
+		}
+	}
+
+	tokens := tokenize(raw)
+	if len(tokens) > 0 && tokens[0] == "через" {
+		return parseRelative(tokens[1:], now)
+	}
+	return parseAbsolute(tokens, now)
+}
+
+func tokenize(s string) []string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "ё", "е")
+	s = spaceCommas(s)
+	s = strings.NewReplacer("!", " ", "?", " ", ";", " ").Replace(s)
+	var tokens []string
+	for _, t := range strings.Fields(s) {
+		t = strings.TrimSuffix(t, ".")
+		if t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// spaceCommas заменяет запятые пробелами, кроме десятичных — между двумя
+// цифрами, как в «через 1,5 часа».
+func spaceCommas(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c == ',' && !(i > 0 && isDigit(b[i-1]) && i+1 < len(b) && isDigit(b[i+1])) {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseRelative разбирает «через 2 часа», «через полчаса», «через 1 час 30 минут»,
+// «через 3 дня в 12:00».
+func parseRelative(tokens []string, now time.Time) (Result, error) {
+	var offset time.Duration
+	var days int
+	amount := -1.0
+	i := 0
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t == "полчаса":
+			offset += 30 * time.Minute
+			continue
+		case t == "полтора" || t == "полторы":
+			amount = 1.5
+			continue
+		case reNumber.MatchString(t):
+			n, err := strconv.ParseFloat(strings.Replace(t, ",", ".", 1), 64)
+			if err != nil {
+				return Result{}, fmt.Errorf("не понял число «%s»", t)
+			}
+			amount = n
+			continue
+		case t == "и":
+			continue
+		}
+
+		n := amount
+		if n < 0 {
+			n = 1 // «через час», «через день»
+		}
+		unit, ok := relativeUnit(t)
+		if !ok {
+			break
+		}
+		if unit == 24*time.Hour && n == float64(int(n)) {
+			days += int(n)
+		} else {
+			offset += time.Duration(n * float64(unit))
+		}
+		amount = -1
+	}
+	if amount >= 0 {
+		return Result{}, errors.New("после числа нужна единица: минуты, часы или дни")
+	}
+	if offset == 0 && days == 0 {
+		return Result{}, errors.New("не понял, через сколько публиковать")
+	}
+
+	t := now.AddDate(0, 0, days).Add(offset)
+	if i == len(tokens) {
+		return Result{Time: t.Truncate(time.Minute)}, nil
+	}
+
+	// Остаток — время суток для «через 3 дня в 12:00»
+	clock, err := parseClock(tokens[i:])
+	if err != nil {
+		return Result{}, err
+	}
+	if !clock.set {
+		return Result{}, fmt.Errorf("не понял «%s»", strings.Join(tokens[i:], " "))
+	}
+	res := Result{Time: time.Date(t.Year(), t.Month(), t.Day(), clock.hour, clock.minute, 0, 0, t.Location())}
+	res.Ambiguous, res.Reason = clock.ambiguous, clock.reason
+	return res, nil
+}
+
+func relativeUnit(t string) (time.Duration, bool) {
+	switch {
+	case t == "мин" || strings.HasPrefix(t, "минут"):
+		return time.Minute, true
+	case t == "ч" || strings.HasPrefix(t, "час"):
+		return time.Hour, true
+	case t == "день" || t == "дня" || t == "дней" || strings.HasPrefix(t, "сут"):
+		return 24 * time.Hour, true
+	case strings.HasPrefix(t, "недел"):
+		return 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// clock — время суток, собранное из токенов.
+type clock struct {
+	set       bool
+	hour      int
+	minute    int
+	ambiguous bool
+	reason    string
+}
+
+// parseClock разбирает время суток: «18:00», «в 9 вечера», «утром», «вечером в 7».
+func parseClock(tokens []string) (clock, error) {
+	var c clock
+	part := ""
+	bareHour := false
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if fillers[t] {
+			continue
+		}
+		if m := reClock.FindStringSubmatch(t); m != nil {
+			h, _ := strconv.Atoi(m[1])
+			min, _ := strconv.Atoi(m[2])
+			if h > 23 || min > 59 {
+				return clock{}, fmt.Errorf("нет такого времени «%s»", t)
+			}
+			c.set, c.hour, c.minute = true, h, min
+			continue
+		}
+		if _, ok := partsOfDay[t]; ok {
+			part = t
+			continue
+		}
+		switch t {
+		case "утра", "дня", "вечера", "ночи":
+			part = t
+			continue
+		case "полдень":
+			c.set, c.hour, c.minute = true, 12, 0
+			continue
+		}
+		if h, err := strconv.Atoi(t); err == nil && h <= 23 {
+			c.set, c.hour, c.minute = true, h, 0
+			bareHour = true
+			continue
+		}
+		return clock{}, fmt.Errorf("не понял «%s»", t)
+	}
+
+	switch {
+	case c.set && part != "":
+		c.hour = applyPart(c.hour, part)
+	case c.set && bareHour && c.hour >= 1 && c.hour <= 11:
+		c.ambiguous = true
+		c.reason = fmt.Sprintf("«%d» без «утра» или «вечера» — считаю, что утра", c.hour)
+	case !c.set && part != "":
+		hour, ok := partsOfDay[part]
+		if !ok {
+			return clock{}, fmt.Errorf("укажите час для «%s»", part)
+		}
+		c.set, c.hour, c.minute = true, hour, 0
+		c.ambiguous = true
+		c.reason = fmt.Sprintf("«%s» — точное время не указано, беру %02d:00", part, hour)
+	}
+	return c, nil
+}
+
+// applyPart переводит час в 24-часовой формат по уточнению «утра», «вечера» и т.п.
+func applyPart(hour int, part string) int {
+	switch part {
+	case "утра", "утром":
+		if hour == 12 {
+			return 0
+		}
+	case "дня", "днем":
+		if hour <= 6 {
+			return hour + 12
+		}
+	case "вечера", "вечером":
+		if hour < 12 {
+			return hour + 12
+		}
+	case "ночи", "ночью":
+		if hour == 12 {
+			return 0
+		}
+		if hour >= 9 && hour < 12 {
+			return hour + 12
+		}
+	}
+	return hour
+}
+
+// parseAbsolute разбирает день («сегодня», «завтра», «в пятницу», «15 августа»,
+// «18.08») и время суток.
+func parseAbsolute(tokens []string, now time.Time) (Result, error) {
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	var day time.Time
+	daySet := false
+	weekday := -1
+	yearGiven := false
+	var rest []string
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t {
+		case "сегодня":
+			day, daySet = today, true
+			continue
+		case "завтра":
+			day, daySet = today.AddDate(0, 0, 1), true
+			continue
+		case "послезавтра":
+			day, daySet = today.AddDate(0, 0, 2), true
+			continue
+		}
+		if dotClock(t) {
+			rest = append(rest, t)
+			continue
+		}
+		if m := reDate.FindStringSubmatch(t); m != nil {
+			d, _ := strconv.Atoi(m[1])
+			mo, _ := strconv.Atoi(m[2])
+			year := now.Year()
+			if m[3] != "" {
+				year, _ = strconv.Atoi(m[3])
+				if year < 100 {
+					year += 2000
+				}
+				yearGiven = true
+			}
+			date, err := makeDate(year, time.Month(mo), d, loc)
+			if err != nil {
+				return Result{}, err
+			}
+			day, daySet = date, true
+			continue
+		}
+		if m := reISODate.FindStringSubmatch(t); m != nil {
+			y, _ := strconv.Atoi(m[1])
+			mo, _ := strconv.Atoi(m[2])
+			d, _ := strconv.Atoi(m[3])
+			date, err := makeDate(y, time.Month(mo), d, loc)
+			if err != nil {
+				return Result{}, err
+			}
+			day, daySet, yearGiven = date, true, true
+			continue
+		}
+		// «15 августа», «15 августа 2025»
+		if d, err := strconv.Atoi(t); err == nil && i+1 < len(tokens) {
+			if mo, ok := monthOf(tokens[i+1]); ok {
+				year := now.Year()
+				skip := 1
+				if i+2 < len(tokens) {
+					if y, err := strconv.Atoi(tokens[i+2]); err == nil && y >= 2000 {
+						year, yearGiven, skip = y, true, 2
+					}
+				}
+				date, err := makeDate(year, mo, d, loc)
+				if err != nil {
+					return Result{}, err
+				}
+				day, daySet = date, true
+				i += skip
+				continue
+			}
+		}
+		if wd, ok := weekdayOf(t); ok {
+			weekday = int(wd)
+			continue
+		}
+		rest = append(rest, t)
+	}
+
+	c, err := parseClock(rest)
+	if err != nil {
+		return Result{}, err
+	}
+	if !daySet && weekday < 0 && !c.set {
+		return Result{}, errors.New("не нашел ни дня, ни времени")
+	}
+
+	res := Result{Ambiguous: c.ambiguous, Reason: c.reason}
+	if !c.set {
+		c.hour, c.minute = defaultHour, 0
+		res.Ambiguous = true
+		res.Reason = fmt.Sprintf("время не указано, беру %02d:00", defaultHour)
+	}
+	at := func(d time.Time) time.Time {
+		return time.Date(d.Year(), d.Month(), d.Day(), c.hour, c.minute, 0, 0, loc)
+	}
+
+	switch {
+	case daySet:
+		res.Time = at(day)
+		// «15 января» в декабре — это следующий год
+		if !yearGiven && res.Time.Before(now) && day.Before(today) {
+			res.Time = res.Time.AddDate(1, 0, 0)
+		}
+	case weekday >= 0:
+		diff := (weekday - int(now.Weekday()) + 7) % 7
+		res.Time = at(today.AddDate(0, 0, diff))
+		if diff == 0 {
+			if !res.Time.After(now) {
+				res.Time = res.Time.AddDate(0, 0, 7)
+			}
+			res.Ambiguous = true
+			res.Reason = joinReason(res.Reason, "сегодня тот же день недели — проверьте, какая неделя имеется в виду")
+		}
+	default:
+		res.Time = at(today)
+		if !res.Time.After(now) {
+			res.Time = res.Time.AddDate(0, 0, 1)
+			res.Ambiguous = true
+			res.Reason = joinReason(res.Reason, "сегодня это время уже прошло, беру завтра")
+		}
+	}
+	return res, nil
+}
+
+// dotClock сообщает, что «18.00» или «9.30» — время через точку, а не дата:
+// вторая часть равна 00 или больше 12 и не может быть месяцем. «18.05»
+// остается датой.
+func dotClock(t string) bool {
+	m := reClock.FindStringSubmatch(t)
+	if m == nil || !strings.Contains(t, ".") {
+		return false
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	return h <= 23 && min <= 59 && (m[2] == "00" || min > 12)
+}
+
+func makeDate(year int, month time.Month, day int, loc *time.Location) (time.Time, error) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, fmt.Errorf("нет такой даты %02d.%02d", day, month)
+	}
+	d := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if d.Day() != day {
+		return time.Time{}, fmt.Errorf("нет такой даты %02d.%02d.%d", day, month, year)
+	}
+	return d, nil
+}
+
+func weekdayOf(t string) (time.Weekday, bool) {
+	for _, w := range weekdays {
+		// Сокращения вроде «пт» сравниваются целиком, полные названия — по основе
+		if t == w.prefix || (len(w.prefix) > len("пн") && strings.HasPrefix(t, w.prefix)) {
+			return w.day, true
+		}
+	}
+	return 0, false
+}
+
+func monthOf(t string) (time.Month, bool) {
+	for _, m := range months {
+		if strings.HasPrefix(t, m.prefix) {
+			return m.month, true
+		}
+	}
+	return 0, false
+}
+
+func joinReason(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "; " + b
+}
+
+var weekdayNames = [...]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+
+// Format показывает время с днем недели: «пятница, 15.08.2025 18:00».
+func Format(t time.Time) string {
+	return FormatDay(t) + t.Format(" 15:04")
+}
+
+// FormatDay показывает дату с днем недели: «пятница, 15.08.2025».
+func FormatDay(t time.Time) string {
+	return fmt.Sprintf("%s, %s", weekdayNames[t.Weekday()], t.Format("02.01.2006"))
+}
@@ -0,0 +1,65 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	// Среда, 13.08.2025 12:30
+	now := time.Date(2025, time.August, 13, 12, 30, 0, 0, loc)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		input     string
+		want      time.Time
+		ambiguous bool
+	}{
+		{"18:00", at(time.August, 13, 18, 0), false},
+		{"18.00", at(time.August, 13, 18, 0), false},
+		{"9.30", at(time.August, 14, 9, 30), true},
+		{"10:00", at(time.August, 14, 10, 0), true},
+		{"12:30", at(time.August, 14, 12, 30), true},
+		{"18.05", at(time.May, 18, 10, 0).AddDate(1, 0, 0), true},
+		{"18.08 18.00", at(time.August, 18, 18, 0), false},
+		{"сегодня 21:30", at(time.August, 13, 21, 30), false},
+		{"завтра в 18:00", at(time.August, 14, 18, 0), false},
+		{"завтра в 18.00", at(time.August, 14, 18, 0), false},
+		{"в пятницу утром", at(time.August, 15, 9, 0), true},
+		{"15 августа в 9 вечера", at(time.August, 15, 21, 0), false},
+		{"через 2 часа", at(time.August, 13, 14, 30), false},
+		{"через полчаса", at(time.August, 13, 13, 0), false},
+		{"через 1,5 часа", at(time.August, 13, 14, 0), false},
+		{"через 2,5 дня", at(time.August, 16, 0, 30), false},
+		{"завтра, в 18:00", at(time.August, 14, 18, 0), false},
+		{"через 3 дня в 12:00", at(time.August, 16, 12, 0), false},
+		{"20.08.2025 18:00", at(time.August, 20, 18, 0), false},
+		{"2025-08-20T18:00:00+03:00", at(time.August, 20, 18, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			res, err := Parse(tt.input, now)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			if !res.Time.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, res.Time, tt.want)
+			}
+			if res.Ambiguous != tt.ambiguous {
+				t.Errorf("Parse(%q).Ambiguous = %v (%s), want %v", tt.input, res.Ambiguous, res.Reason, tt.ambiguous)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	now := time.Date(2025, time.August, 13, 12, 30, 0, 0, time.UTC)
+	for _, input := range []string{"", "18.70", "31.02", "25:00", "через", "через 2", "когда-нибудь"} {
+		if res, err := Parse(input, now); err == nil {
+			t.Errorf("Parse(%q) = %s, want error", input, res.Time)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/gpt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxEditInstruction ограничивает длину инструкции для AI-правки.
+const maxEditInstruction = 500
+
+// rewriteSystem объясняет модели, как править готовый пост.
+const rewriteSystem = "Ты правишь готовый пост для Телеграм по инструкции редактора. " +
+	"Меняй только то, о чем просят, остальное оставь дословно: стиль, голос автора, абзацы, эмодзи. " +
+	"Отвечай только новым текстом поста, без пояснений и кавычек."
+
+// Rewrite просит модель переписать текст поста по инструкции редактора, например
+// «сделай короче» или «убери эмодзи». Системная инструкция персоны сохраняет
+// голос канала.
+func (u *GenerateUsecase) Rewrite(persona domain.Persona, text, instruction string) (string, error) {
+	system, _ := persona.Render("", time.Now())
+	revised, err := u.generateText(gpt.TextRequest{
+		System:      strings.TrimSpace(system + "\n\n" + rewriteSystem),
+		Prompt:      fmt.Sprintf("Инструкция: %s\n\nПост:\n%s", instruction, text),
+		MaxTokens:   max(800, persona.Length, utf8.RuneCountInString(text)),
+		Temperature: 0.3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("ошибка AI-правки: %w", err)
+	}
+	revised = strings.TrimSpace(revised)
+	if revised == "" {
+		return "", errors.New("модель прислала пустой текст")
+	}
+	return revised, nil
+}
+
+// CheckEditInstruction проверяет инструкцию редактора для AI-правки.
+func CheckEditInstruction(instruction string) error {
+	if utf8.RuneCountInString(instruction) > maxEditInstruction {
+		return fmt.Errorf("инструкция длиннее %d символов", maxEditInstruction)
+	}
+	return nil
+}
+
+// ProposeAIEdit сохраняет правку поста, которую редактор еще не принял.
+func (u *TopicUsecase) ProposeAIEdit(post domain.Post, instruction, revised string) (domain.AIEdit, error) {
+	if !post.Editable() {
+		return domain.AIEdit{}, fmt.Errorf("пост уже %s, текст менять поздно", post.Status.Title())
+	}
+	if revised == post.Text {
+		return domain.AIEdit{}, errors.New("модель не изменила текст")
+	}
+	e := domain.AIEdit{PostID: post.ID, Instruction: instruction, Original: post.Text, Revised: revised, Status: domain.AIEditProposed}
+	id, err := u.repo.CreateAIEdit(e)
+	if err != nil {
+		return domain.AIEdit{}, err
+	}
+	e.ID = id
+	return e, nil
+}
+
+// GetAIEdit возвращает правку поста postID.
+func (u *TopicUsecase) GetAIEdit(postID, editID int64) (domain.AIEdit, error) {
+	e, err := u.repo.GetAIEdit(editID)
+	if err != nil || e.PostID != postID {
+		return domain.AIEdit{}, errors.New("правка не найдена")
+	}
+	return e, nil
+}
+
+// AcceptAIEdit заменяет текст поста правкой. Если текст поста изменился после
+// правки, она не применяется.
+func (u *TopicUsecase) AcceptAIEdit(post domain.Post, editID int64) (domain.Post, error) {
+	e, err := u.GetAIEdit(post.ID, editID)
+	if err != nil {
+		return domain.Post{}, err
+	}
+	if e.Status != domain.AIEditProposed {
+		return domain.Post{}, errors.New("по правке уже принято решение")
+	}
+	if !post.Editable() {
+		return domain.Post{}, fmt.Errorf("пост уже %s, текст менять поздно", post.Status.Title())
+	}
+	if err := u.repo.ApplyAIEdit(e, domain.AIEditProposed, domain.AIEditAccepted, e.Original, e.Revised); err != nil {
+		return domain.Post{}, errors.New("текст поста изменился после правки — попросите правку заново")
+	}
+	return u.repo.GetPost(post.ID)
+}
+
+// RevertAIEdit отклоняет правку. Принятая правка откатывается: посту
+// возвращается текст до нее, если с тех пор его не меняли.
+func (u *TopicUsecase) RevertAIEdit(post domain.Post, editID int64) (domain.Post, error) {
+	e, err := u.GetAIEdit(post.ID, editID)
+	if err != nil {
+		return domain.Post{}, err
+	}
+	switch e.Status {
+	case domain.AIEditProposed:
+		if err := u.repo.ApplyAIEdit(e, domain.AIEditProposed, domain.AIEditReverted, e.Original, e.Original); err != nil {
+			return domain.Post{}, errors.New("по правке уже принято решение")
+		}
+	case domain.AIEditAccepted:
+		if !post.Editable() {
+			return domain.Post{}, fmt.Errorf("пост уже %s, текст менять поздно", post.Status.Title())
+		}
+		if err := u.repo.ApplyAIEdit(e, domain.AIEditAccepted, domain.AIEditReverted, e.Revised, e.Original); err != nil {
+			return domain.Post{}, errors.New("текст поста изменился после правки — откатить ее нельзя")
+		}
+	default:
+		return domain.Post{}, errors.New("правка уже откачена")
+	}
+	return u.repo.GetPost(post.ID)
+}
+
+// SavePendingAIEdit запоминает, что следующее сообщение чата — инструкция для AI-правки поста.
+func (u *TopicUsecase) SavePendingAIEdit(chatID, postID int64) error {
+	return u.repo.SavePendingAIEdit(chatID, postID)
+}
+
+// GetPendingAIEdit возвращает пост, ожидающий инструкции.
+func (u *TopicUsecase) GetPendingAIEdit(chatID int64) (int64, error) {
+	postID, err := u.repo.GetPendingAIEdit(chatID)
+	if err != nil {
+		return 0, errors.New("нет ожидания AI-правки")
+	}
+	return postID, nil
+}
+
+// ClearPendingAIEdit очищает ожидание инструкции для AI-правки.
+func (u *TopicUsecase) ClearPendingAIEdit(chatID int64) error {
+	return u.repo.ClearPendingAIEdit(chatID)
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/repository"
+)
+
+// ChannelUsecase управляет реестром каналов и каналом по умолчанию для редакторов.
+type ChannelUsecase struct {
+	repo  *repository.ChannelRepository
+	users *repository.UserRepository
+}
+
+// NewChannelUsecase создает новый экземпляр ChannelUsecase.
+func NewChannelUsecase(r *repository.ChannelRepository, users *repository.UserRepository) *ChannelUsecase {
+	return &ChannelUsecase{repo: r, users: users}
+}
+
+// Bind привязывает канал. Первый канал редактора становится его каналом по умолчанию.
+func (u *ChannelUsecase) Bind(userID int64, ch domain.Channel) error {
+	if ch.ID == 0 {
+		return errors.New("не указан ID канала")
+	}
+	ch.AddedBy = userID
+	if err := u.repo.Save(ch); err != nil {
+		return err
+	}
+	user, err := u.users.Get(userID)
+	if err != nil {
+		return err
+	}
+	if user.DefaultChannelID == 0 {
+		return u.users.SetDefaultChannel(userID, ch.ID)
+	}
+	return nil
+}
+
+// Unbind отвязывает канал, если в него не запланированы посты.
+func (u *ChannelUsecase) Unbind(channelID int64) error {
+	scheduled, err := u.repo.CountScheduled(channelID)
+	if err != nil {
+		return err
+	}
+	if scheduled > 0 {
+		return fmt.Errorf("в канал запланировано постов: %d — сначала перенесите или отмените их в /queue", scheduled)
+	}
+	return u.repo.Delete(channelID)
+}
+
+// List возвращает все привязанные каналы.
+func (u *ChannelUsecase) List() ([]domain.Channel, error) {
+	return u.repo.List()
+}
+
+// Get возвращает канал по ID.
+func (u *ChannelUsecase) Get(channelID int64) (domain.Channel, error) {
+	return u.repo.Get(channelID)
+}
+
+// SetDefault делает канал каналом по умолчанию для редактора.
+func (u *ChannelUsecase) SetDefault(userID, channelID int64) error {
+	if _, err := u.repo.Get(channelID); err != nil {
+		return err
+	}
+	return u.users.SetDefaultChannel(userID, channelID)
+}
+
+// DefaultChannel возвращает ID канала по умолчанию для редактора. Если он не
+// выбран, но канал в реестре ровно один, используется этот канал.
+func (u *ChannelUsecase) DefaultChannel(userID int64) (int64, error) {
+	user, err := u.users.Get(userID)
+	if err != nil {
+		return 0, err
+	}
+	if user.DefaultChannelID != 0 {
+		return user.DefaultChannelID, nil
+	}
+	channels, err := u.repo.List()
+	if err != nil {
+		return 0, err
+	}
+	if len(channels) == 1 {
+		return channels[0].ID, nil
+	}
+	return 0, errors.New("канал по умолчанию не выбран: привяжите канал через /bind или выберите его в /channels")
+}
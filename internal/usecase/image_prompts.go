@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/gpt"
+	"strings"
+	"sync"
+)
+
+// imagePromptSystem объясняет текстовой модели, какие описания нужны генератору картинок.
+const imagePromptSystem = "You write prompts for an image generation model. " +
+	"Read a Russian Telegram post and describe scenes that illustrate it: concrete subjects, setting, light, composition. " +
+	"Write in English, one or two sentences per prompt, no text or letters in the image, no real people's names. " +
+	"Reply with a JSON array of strings only."
+
+// ImagePrompts просит текстовую модель придумать count описаний картинок к посту
+// на английском. Стиль персоны добавляется к каждому описанию, чтобы картинки
+// канала выглядели единообразно.
+func (u *GenerateUsecase) ImagePrompts(persona domain.Persona, text string, count int) ([]string, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+	if count > domain.MaxPostImages {
+		count = domain.MaxPostImages
+	}
+	prompt := fmt.Sprintf("Write %d different image prompts for this post, following its story from beginning to end.", count)
+	if persona.ImageStyle != "" {
+		prompt += " Visual style of the channel: " + persona.ImageStyle + "."
+	}
+	prompt += "\n\nPost:\n" + text
+
+	answer, err := u.generateText(gpt.TextRequest{
+		System:      imagePromptSystem,
+		Prompt:      prompt,
+		MaxTokens:   120 * count,
+		Temperature: 0.7,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка описания картинок: %w", err)
+	}
+	prompts := parseImagePrompts(answer)
+	if len(prompts) == 0 {
+		return nil, errors.New("модель не прислала описаний картинок")
+	}
+	if len(prompts) > count {
+		prompts = prompts[:count]
+	}
+	return withImageStyle(persona, prompts), nil
+}
+
+// withImageStyle дописывает к описаниям стиль картинок персоны.
+func withImageStyle(persona domain.Persona, prompts []string) []string {
+	if persona.ImageStyle == "" {
+		return prompts
+	}
+	for i := range prompts {
+		prompts[i] = strings.TrimRight(prompts[i], ". ") + ". Style: " + persona.ImageStyle
+	}
+	return prompts
+}
+
+// parseImagePrompts достает описания из ответа модели: JSON-массив, возможно в
+// блоке кода, а если модель ответила списком — непустые строки без нумерации.
+func parseImagePrompts(answer string) []string {
+	answer = strings.TrimSpace(answer)
+	if start, end := strings.Index(answer, "["), strings.LastIndex(answer, "]"); start >= 0 && end > start {
+		var prompts []string
+		if err := json.Unmarshal([]byte(answer[start:end+1]), &prompts); err == nil {
+			return nonEmpty(prompts)
+		}
+	}
+	var prompts []string
+	for _, line := range strings.Split(answer, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		line = strings.TrimLeft(line, "-*•0123456789.) ")
+		prompts = append(prompts, strings.Trim(line, `"`))
+	}
+	return nonEmpty(prompts)
+}
+
+func nonEmpty(items []string) []string {
+	var res []string
+	for _, s := range items {
+		if s = strings.TrimSpace(s); s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// GenerateImages параллельно рисует картинки по описаниям. Квота проверяется
+// один раз сразу на все картинки. Если часть картинок не удалась, возвращает
+// остальные в исходном порядке вместе с ошибкой: они уже оплачены.
+func (u *GenerateUsecase) GenerateImages(prompts []string) ([]domain.PostImage, error) {
+	if err := u.allowImages(len(prompts)); err != nil {
+		return nil, err
+	}
+	drawn := make([]domain.PostImage, len(prompts))
+	errs := make([]error, len(prompts))
+	var wg sync.WaitGroup
+	for i, prompt := range prompts {
+		wg.Add(1)
+		go func(i int, prompt string) {
+			defer wg.Done()
+			url, err := u.drawImage(prompt)
+			drawn[i] = domain.PostImage{URL: url, Prompt: prompt}
+			if err != nil {
+				errs[i] = fmt.Errorf("ошибка генерации картинки %d: %w", i+1, err)
+			}
+		}(i, prompt)
+	}
+	wg.Wait()
+
+	var images []domain.PostImage
+	for i, img := range drawn {
+		if errs[i] == nil {
+			images = append(images, img)
+		}
+	}
+	return images, errors.Join(errs...)
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"errors"
+	"lady/config"
+	"lady/internal/gpt"
+	"lady/internal/repository"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeImages рисует картинку с адресом по описанию; описания с «ошибка» не удаются.
+type fakeImages struct {
+	calls atomic.Int32
+}
+
+func (f *fakeImages) GenerateImage(req gpt.ImageRequest) (string, error) {
+	f.calls.Add(1)
+	if strings.Contains(req.Prompt, "ошибка") {
+		return "", errors.New("генератор недоступен")
+	}
+	return "https://img/" + req.Prompt, nil
+}
+
+func TestGenerateImagesChecksQuotaOnce(t *testing.T) {
+	usage := NewUsageUsecase(repository.NewUsageRepository(newTestDB(t)), config.UsageConfig{DailyImages: 2})
+	images := &fakeImages{}
+	gen := NewGenerateUsecase(nil, images, usage).For(1, 0)
+
+	if _, err := gen.GenerateImages([]string{"a", "b", "c"}); err == nil || !strings.Contains(err.Error(), "лимит картинок") {
+		t.Fatalf("GenerateImages over the quota = %v, want quota error", err)
+	}
+	if n := images.calls.Load(); n != 0 {
+		t.Fatalf("generator called %d times after the quota check failed", n)
+	}
+
+	got, err := gen.GenerateImages([]string{"a", "b"})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("GenerateImages within the quota = %d images, %v", len(got), err)
+	}
+	if _, err := gen.GenerateImages([]string{"c"}); err == nil {
+		t.Error("GenerateImages after the quota was spent succeeded")
+	}
+}
+
+func TestGenerateImagesKeepsDrawn(t *testing.T) {
+	gen := NewGenerateUsecase(nil, &fakeImages{}, nil)
+	got, err := gen.GenerateImages([]string{"a", "ошибка", "c"})
+	if err == nil || !strings.Contains(err.Error(), "картинки 2") {
+		t.Errorf("GenerateImages error = %v, want it to name image 2", err)
+	}
+	if len(got) != 2 || got[0].Prompt != "a" || got[1].Prompt != "c" {
+		t.Errorf("GenerateImages kept %+v, want images a and c", got)
+	}
+}
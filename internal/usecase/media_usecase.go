@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/media"
+	"lady/internal/repository"
+	"log"
+)
+
+// MediaUsecase сохраняет картинки постов на диск, пока их адреса не истекли.
+type MediaUsecase struct {
+	repo  *repository.MediaRepository
+	store *media.Store
+}
+
+// NewMediaUsecase создает новый экземпляр MediaUsecase.
+func NewMediaUsecase(r *repository.MediaRepository, store *media.Store) *MediaUsecase {
+	return &MediaUsecase{repo: r, store: store}
+}
+
+// Download скачивает картинку по URL в хранилище. Картинка, уже скачанная по
+// этому URL, берется из хранилища: кэш генератора отдает прежние ссылки, и к
+// повтору они могут истечь.
+func (u *MediaUsecase) Download(url string) (domain.Media, error) {
+	if m, ok, err := u.repo.FindBySource(url); err != nil {
+		log.Printf("Ошибка поиска медиа по адресу: %v", err)
+	} else if ok {
+		return m, nil
+	}
+	return u.download(url, url)
+}
+
+// download скачивает картинку и запоминает sourceURL как ее источник.
+func (u *MediaUsecase) download(url, sourceURL string) (domain.Media, error) {
+	file, err := u.store.Download(url)
+	if err != nil {
+		return domain.Media{}, err
+	}
+	m := domain.Media{Path: file.Path, SourceURL: sourceURL, MimeType: file.MimeType, Size: file.Size}
+	if m.ID, err = u.repo.Save(m); err != nil {
+		return domain.Media{}, err
+	}
+	return u.repo.Get(m.ID)
+}
+
+// Import сохраняет в хранилище фото, присланное редактором. file_id уже известен,
+// поэтому при публикации файл не придется загружать заново.
+func (u *MediaUsecase) Import(url, fileID string) (domain.PostImage, error) {
+	// Ссылка на файл Telegram содержит токен бота, поэтому источник не сохраняем
+	m, err := u.download(url, "")
+	if err != nil {
+		return domain.PostImage{}, err
+	}
+	if m.FileID == "" {
+		u.RememberFileID(m.ID, fileID)
+		m.FileID = fileID
+	}
+	return domain.PostImage{MediaID: m.ID, Path: m.Path, FileID: m.FileID}, nil
+}
+
+// Localize скачивает картинки, которые еще не лежат в хранилище, и возвращает
+// их со ссылками на файлы.
+func (u *MediaUsecase) Localize(images []domain.PostImage) ([]domain.PostImage, error) {
+	res := make([]domain.PostImage, len(images))
+	for i, img := range images {
+		res[i] = img
+		if img.MediaID != 0 || img.URL == "" {
+			continue
+		}
+		m, err := u.Download(img.URL)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сохранения картинки %d: %w", i+1, err)
+		}
+		res[i].MediaID = m.ID
+		res[i].Path = m.Path
+		res[i].FileID = m.FileID
+	}
+	return res, nil
+}
+
+// RememberFileID запоминает file_id Telegram, чтобы в следующий раз не загружать файл.
+func (u *MediaUsecase) RememberFileID(mediaID int64, fileID string) {
+	if mediaID == 0 || fileID == "" {
+		return
+	}
+	if err := u.repo.SetFileID(mediaID, fileID); err != nil {
+		log.Printf("file_id медиа %d не сохранен: %v", mediaID, err)
+	}
+}
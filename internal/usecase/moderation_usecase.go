@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"lady/internal/domain"
+	"lady/internal/gpt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ModerationRule — локальное правило модерации: стоп-слово или регулярное выражение.
+type ModerationRule struct {
+	Name   string // как правило показывается редактору
+	Action domain.ModerationAction
+	re     *regexp.Regexp
+}
+
+// ParseModerationAction разбирает действие block или flag; пустая строка дает block.
+func ParseModerationAction(s string) (domain.ModerationAction, error) {
+	switch domain.ModerationAction(strings.ToLower(strings.TrimSpace(s))) {
+	case "", domain.ModerationBlock:
+		return domain.ModerationBlock, nil
+	case domain.ModerationFlag:
+		return domain.ModerationFlag, nil
+	}
+	return "", fmt.Errorf("неизвестное действие модерации %q (block, flag)", s)
+}
+
+// ParseModerationRules читает правила, по одному на строку:
+//
+//	# комментарий
+//	казино                 — стоп-слово, пост блокируется
+//	flag: ставки           — пост публикуется только после подтверждения
+//	block: re:t\.me/\S+    — регулярное выражение
+//
+// Стоп-слова ищутся целыми словами без учета регистра. source подписывает
+// правила в сообщениях редактору.
+func ParseModerationRules(r io.Reader, source string) ([]ModerationRule, error) {
+	var rules []ModerationRule
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		action := domain.ModerationBlock
+		if prefix, rest, ok := strings.Cut(text, ":"); ok {
+			switch domain.ModerationAction(strings.TrimSpace(prefix)) {
+			case domain.ModerationBlock:
+				text = strings.TrimSpace(rest)
+			case domain.ModerationFlag:
+				action, text = domain.ModerationFlag, strings.TrimSpace(rest)
+			}
+		}
+		if text == "" {
+			return nil, fmt.Errorf("%s:%d: пустое правило", source, line)
+		}
+
+		rule := ModerationRule{Action: action}
+		var err error
+		if expr, ok := strings.CutPrefix(text, "re:"); ok {
+			rule.Name = fmt.Sprintf("выражение «%s» (%s:%d)", expr, source, line)
+			rule.re, err = regexp.Compile("(?i)" + expr)
+		} else {
+			rule.Name = fmt.Sprintf("стоп-слово «%s» (%s:%d)", text, source, line)
+			rule.re, err = regexp.Compile(`(?i)(^|[^\p{L}\p{N}_])` + regexp.QuoteMeta(text) + `($|[^\p{L}\p{N}_])`)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", source, line, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// LoadModerationRules читает правила из файла; пустой путь — правил нет.
+func LoadModerationRules(path string) ([]ModerationRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения правил модерации: %w", err)
+	}
+	defer f.Close()
+	return ParseModerationRules(f, filepath.Base(path))
+}
+
+// ModerationUsecase проверяет посты перед публикацией: локальными правилами и,
+// если настроен, сервисом модерации провайдера.
+type ModerationUsecase struct {
+	rules        []ModerationRule
+	remote       gpt.Moderator // nil — только локальные правила
+	remoteAction domain.ModerationAction
+}
+
+// NewModerationUsecase создает новый экземпляр ModerationUsecase. remoteAction —
+// что делать с постом, который отметил сервис модерации.
+func NewModerationUsecase(rules []ModerationRule, remote gpt.Moderator, remoteAction domain.ModerationAction) *ModerationUsecase {
+	return &ModerationUsecase{rules: rules, remote: remote, remoteAction: remoteAction}
+}
+
+// Check проверяет текст и картинки поста. Если сервис модерации недоступен,
+// пост отмечается для ручной проверки, а не уходит в канал непроверенным.
+func (u *ModerationUsecase) Check(post domain.Post) domain.ModerationVerdict {
+	var verdict domain.ModerationVerdict
+	for _, rule := range u.rules {
+		if rule.re.MatchString(post.Text) {
+			verdict.Add(rule.Action, rule.Name)
+		}
+	}
+	if u.remote == nil {
+		return verdict
+	}
+
+	req := gpt.ModerationRequest{Text: post.Text}
+	for _, img := range post.Images {
+		if url := moderationImage(img); url != "" {
+			req.Images = append(req.Images, url)
+		}
+	}
+	res, err := u.remote.Moderate(req)
+	if err != nil {
+		log.Printf("Ошибка модерации поста %d: %v", post.ID, err)
+		verdict.Add(domain.ModerationFlag, fmt.Sprintf("сервис модерации недоступен: %v", err))
+		return verdict
+	}
+	if res.Flagged {
+		categories := "без категории"
+		if len(res.Categories) > 0 {
+			categories = strings.Join(res.Categories, ", ")
+		}
+		verdict.Add(u.remoteAction, "сервис модерации: "+categories)
+	}
+	return verdict
+}
+
+// moderationImage возвращает картинку для сервиса модерации: файл из
+// хранилища как data:-URL, иначе исходный адрес.
+func moderationImage(img domain.PostImage) string {
+	if img.Path != "" {
+		data, err := os.ReadFile(img.Path)
+		if err == nil {
+			return "data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data)
+		}
+		log.Printf("Ошибка чтения картинки %s для модерации: %v", img.Path, err)
+	}
+	if strings.HasPrefix(img.URL, "http") {
+		return img.URL
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/repository"
+	"strings"
+)
+
+const (
+	minPersonaLength = 100
+	maxPersonaLength = 4096
+	// DefaultImageCount — сколько картинок рисует новая персона, если не указано иное.
+	DefaultImageCount = 2
+)
+
+// PersonaUsecase управляет персонами и их выбором для каналов.
+type PersonaUsecase struct {
+	repo *repository.PersonaRepository
+}
+
+// NewPersonaUsecase создает новый экземпляр PersonaUsecase.
+func NewPersonaUsecase(r *repository.PersonaRepository) *PersonaUsecase {
+	return &PersonaUsecase{repo: r}
+}
+
+// personaName приводит имя к нижнему регистру: NOCASE в SQLite не работает с кириллицей.
+func personaName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func validatePersona(p domain.Persona) error {
+	if p.Name == "" || strings.ContainsAny(p.Name, " \t\n") {
+		return errors.New("имя персоны должно быть одним словом")
+	}
+	if !strings.Contains(p.Template, "{topic}") {
+		return errors.New("в шаблоне нет переменной {topic} — модель не узнает тему")
+	}
+	if p.Length < minPersonaLength || p.Length > maxPersonaLength {
+		return fmt.Errorf("длина должна быть от %d до %d символов", minPersonaLength, maxPersonaLength)
+	}
+	if p.ImageCount < 0 || p.ImageCount > domain.MaxPostImages {
+		return fmt.Errorf("картинок может быть от 0 до %d", domain.MaxPostImages)
+	}
+	return nil
+}
+
+// Create сохраняет новую персону.
+func (u *PersonaUsecase) Create(userID int64, p domain.Persona) (domain.Persona, error) {
+	p.Name = personaName(p.Name)
+	if p.Length == 0 {
+		p.Length = 1024
+	}
+	if err := validatePersona(p); err != nil {
+		return domain.Persona{}, err
+	}
+	if _, err := u.repo.FindByName(p.Name); err == nil {
+		return domain.Persona{}, fmt.Errorf("персона «%s» уже есть, измените ее через /persona edit", p.Name)
+	}
+	p.CreatedBy = userID
+	id, err := u.repo.Create(p)
+	if err != nil {
+		return domain.Persona{}, err
+	}
+	p.ID = id
+	return p, nil
+}
+
+// Update сохраняет изменения персоны.
+func (u *PersonaUsecase) Update(p domain.Persona) error {
+	if err := validatePersona(p); err != nil {
+		return err
+	}
+	return u.repo.Update(p)
+}
+
+// Find возвращает персону по имени.
+func (u *PersonaUsecase) Find(name string) (domain.Persona, error) {
+	return u.repo.FindByName(personaName(name))
+}
+
+// List возвращает все персоны.
+func (u *PersonaUsecase) List() ([]domain.Persona, error) {
+	return u.repo.List()
+}
+
+// ChannelPersonas возвращает ID выбранной персоны для каждого канала.
+func (u *PersonaUsecase) ChannelPersonas() (map[int64]int64, error) {
+	return u.repo.ChannelPersonas()
+}
+
+// ForChannel возвращает персону канала, а если она не выбрана — первую персону.
+func (u *PersonaUsecase) ForChannel(channelID int64) (domain.Persona, error) {
+	if channelID != 0 {
+		if p, err := u.repo.ForChannel(channelID); err == nil {
+			return p, nil
+		}
+	}
+	return u.repo.First()
+}
+
+// Use делает персону активной для канала.
+func (u *PersonaUsecase) Use(channelID int64, name string) (domain.Persona, error) {
+	p, err := u.repo.FindByName(personaName(name))
+	if err != nil {
+		return domain.Persona{}, err
+	}
+	if err := u.repo.SetChannelPersona(channelID, p.ID); err != nil {
+		return domain.Persona{}, err
+	}
+	return p, nil
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"lady/internal/domain"
+)
+
+// editableImages возвращает пост, если его картинки еще можно менять.
+func (u *TopicUsecase) editableImages(postID int64) (domain.Post, error) {
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return domain.Post{}, errors.New("пост не найден")
+	}
+	if !p.Editable() {
+		return domain.Post{}, fmt.Errorf("пост уже %s, картинки менять поздно", p.Status.Title())
+	}
+	return p, nil
+}
+
+// AddImages добавляет картинки в конец поста; при replace старые картинки убираются.
+func (u *TopicUsecase) AddImages(postID int64, images []domain.PostImage, replace bool) (domain.Post, error) {
+	p, err := u.editableImages(postID)
+	if err != nil {
+		return domain.Post{}, err
+	}
+	if replace {
+		p.Images = nil
+	}
+	return u.SetPostImages(postID, append(p.Images, images...))
+}
+
+// ReplaceImage заменяет картинку с номером index (с нуля).
+func (u *TopicUsecase) ReplaceImage(postID int64, index int, img domain.PostImage) (domain.Post, error) {
+	p, err := u.editableImages(postID)
+	if err != nil {
+		return domain.Post{}, err
+	}
+	if index < 0 || index >= len(p.Images) {
+		return domain.Post{}, fmt.Errorf("в посте нет картинки %d", index+1)
+	}
+	p.Images[index] = img
+	return u.SetPostImages(postID, p.Images)
+}
+
+// MoveImage переставляет картинку index на место to.
+func (u *TopicUsecase) MoveImage(postID int64, index, to int) (domain.Post, error) {
+	p, err := u.editableImages(postID)
+	if err != nil {
+		return domain.Post{}, err
+	}
+	if index < 0 || index >= len(p.Images) || to < 0 || to >= len(p.Images) {
+		return domain.Post{}, errors.New("картинку некуда передвинуть")
+	}
+	img := p.Images[index]
+	images := append(p.Images[:index:index], p.Images[index+1:]...)
+	images = append(images[:to], append([]domain.PostImage{img}, images[to:]...)...)
+	return u.SetPostImages(postID, images)
+}
+
+// RemoveImage убирает картинку index из поста.
+func (u *TopicUsecase) RemoveImage(postID int64, index int) (domain.Post, error) {
+	p, err := u.editableImages(postID)
+	if err != nil {
+		return domain.Post{}, err
+	}
+	if index < 0 || index >= len(p.Images) {
+		return domain.Post{}, fmt.Errorf("в посте нет картинки %d", index+1)
+	}
+	return u.SetPostImages(postID, append(p.Images[:index:index], p.Images[index+1:]...))
+}
+
+// ImageIndex возвращает номер картинки поста, сохраненной в файле mediaID.
+func ImageIndex(post domain.Post, mediaID int64) (int, error) {
+	for i, img := range post.Images {
+		if mediaID != 0 && img.MediaID == mediaID {
+			return i, nil
+		}
+	}
+	return -1, errors.New("картинки уже нет в посте")
+}
+
+// SavePendingImagePrompt запоминает, что следующее сообщение чата — новое описание картинки.
+func (u *TopicUsecase) SavePendingImagePrompt(chatID, postID, mediaID int64, messageID int) error {
+	return u.repo.SavePendingImagePrompt(chatID, postID, mediaID, messageID)
+}
+
+// GetPendingImagePrompt возвращает пост, картинку и сообщение превью, ожидающие нового описания.
+func (u *TopicUsecase) GetPendingImagePrompt(chatID int64) (postID, mediaID int64, messageID int, err error) {
+	postID, mediaID, messageID, err = u.repo.GetPendingImagePrompt(chatID)
+	if err != nil {
+		return 0, 0, 0, errors.New("нет ожидания описания картинки")
+	}
+	return postID, mediaID, messageID, nil
+}
+
+// ClearPendingImagePrompt очищает ожидание описания картинки.
+func (u *TopicUsecase) ClearPendingImagePrompt(chatID int64) error {
+	return u.repo.ClearPendingImagePrompt(chatID)
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/gpt"
+	"lady/internal/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// seriesContextPosts — сколько последних опубликованных постов серии пересказывается модели.
+	seriesContextPosts = 3
+	maxSeriesTitle     = 100
+)
+
+// SeriesUsecase управляет сериями постов и выбором серии редактором.
+type SeriesUsecase struct {
+	repo  *repository.SeriesRepository
+	users *repository.UserRepository
+}
+
+// NewSeriesUsecase создает новый экземпляр SeriesUsecase.
+func NewSeriesUsecase(r *repository.SeriesRepository, users *repository.UserRepository) *SeriesUsecase {
+	return &SeriesUsecase{repo: r, users: users}
+}
+
+// Create заводит серию и сразу делает ее текущей для редактора.
+func (u *SeriesUsecase) Create(userID int64, title string) (domain.Series, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return domain.Series{}, errors.New("у серии должно быть название")
+	}
+	if utf8.RuneCountInString(title) > maxSeriesTitle {
+		return domain.Series{}, fmt.Errorf("название серии длиннее %d символов", maxSeriesTitle)
+	}
+	id, err := u.repo.Create(domain.Series{Title: title, CreatedBy: userID})
+	if err != nil {
+		return domain.Series{}, err
+	}
+	return u.Use(userID, id)
+}
+
+// Use делает серию текущей: новые посты редактора будут ее продолжать.
+func (u *SeriesUsecase) Use(userID, seriesID int64) (domain.Series, error) {
+	s, err := u.repo.Get(seriesID)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	if err := u.users.SetSeries(userID, s.ID); err != nil {
+		return domain.Series{}, err
+	}
+	return s, nil
+}
+
+// Stop выводит редактора из серии: новые посты снова пишутся сами по себе.
+func (u *SeriesUsecase) Stop(userID int64) error {
+	return u.users.SetSeries(userID, 0)
+}
+
+// Active возвращает ID текущей серии редактора; 0 — редактор пишет вне серий.
+func (u *SeriesUsecase) Active(userID int64) int64 {
+	user, _ := u.users.Get(userID)
+	return user.SeriesID
+}
+
+// Get возвращает серию по ID.
+func (u *SeriesUsecase) Get(seriesID int64) (domain.Series, error) {
+	return u.repo.Get(seriesID)
+}
+
+// List возвращает все серии.
+func (u *SeriesUsecase) List() ([]domain.Series, error) {
+	return u.repo.List()
+}
+
+// Previous возвращает тексты последних опубликованных постов серии по порядку.
+func (u *SeriesUsecase) Previous(seriesID int64) ([]string, error) {
+	return u.repo.PublishedTexts(seriesID, seriesContextPosts)
+}
+
+// InSeries возвращает генератор, который пишет следующий пост серии: модель
+// получает краткий пересказ предыдущих постов. Для серии без опубликованных
+// постов генератор не меняется.
+func (u *GenerateUsecase) InSeries(series domain.Series, previous []string) (*GenerateUsecase, error) {
+	if len(previous) == 0 {
+		return u, nil
+	}
+	var posts strings.Builder
+	for i, text := range previous {
+		posts.WriteString(fmt.Sprintf("Пост %d:\n%s\n\n", i+1, text))
+	}
+	// Пересказ тех же постов берем из кэша даже при перегенерации
+	summarizer := *u
+	summarizer.fresh = false
+	summary, err := summarizer.generateText(gpt.TextRequest{
+		System: "Ты редактор серии постов для Телеграм. Отвечай только пересказом, без пояснений.",
+		Prompt: fmt.Sprintf("Кратко, до 600 символов, перескажи посты серии «%s»: героев, что произошло, "+
+			"чем закончился последний пост и какое продолжение он пообещал.\n\n%s", series.Title, posts.String()),
+		MaxTokens:   600,
+		Temperature: 0.3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка пересказа серии: %w", err)
+	}
+
+	next := *u
+	next.seriesContext = fmt.Sprintf("Это следующий пост серии «%s». Что было раньше:\n%s\n\n"+
+		"Продолжи историю с того места, где остановился последний пост, и сдержи обещание его финального крючка. "+
+		"Не пересказывай прошлые посты.", series.Title, strings.TrimSpace(summary))
+	return &next, nil
+}
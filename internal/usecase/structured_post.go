@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/gpt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// structuredAttempts — сколько ответов модели проверять, прежде чем сдаться:
+	// первый и просьбы исправить его.
+	structuredAttempts = 3
+	maxHashtags        = 5
+	// minScheduleLead — насколько позже текущего момента может быть предложенное время.
+	minScheduleLead = 10 * time.Minute
+)
+
+// GeneratedPost — пост, написанный моделью. В формате json модель сразу
+// предлагает описания картинок и время публикации.
+type GeneratedPost struct {
+	Text         string
+	ImagePrompts []string  // пусто — описания придумываются отдельно
+	PublishAt    time.Time // нулевое — модель время не предложила
+}
+
+// StructuredPost — ответ модели в формате json.
+type StructuredPost struct {
+	Hook         string   `json:"hook"`
+	Body         string   `json:"body"`
+	ClosingHook  string   `json:"closing_hook"`
+	Hashtags     []string `json:"hashtags"`
+	ImagePrompts []string `json:"image_prompts"`
+	PublishTime  string   `json:"publish_time"` // ЧЧ:ММ по времени редактора
+}
+
+// Text собирает из частей текст поста.
+func (p StructuredPost) Text() string {
+	parts := []string{p.Hook, p.Body, p.ClosingHook}
+	if len(p.Hashtags) > 0 {
+		parts = append(parts, strings.Join(p.Hashtags, " "))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// structuredSchema — схема ответа модели в формате json.
+var structuredSchema = &gpt.JSONSchema{
+	Name: "telegram_post",
+	Schema: map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"hook", "body", "closing_hook", "hashtags", "image_prompts", "publish_time"},
+		"properties": map[string]interface{}{
+			"hook":         map[string]interface{}{"type": "string", "description": "первая фраза-приманка"},
+			"body":         map[string]interface{}{"type": "string", "description": "основной текст поста"},
+			"closing_hook": map[string]interface{}{"type": "string", "description": "финальный крючок, который заставляет ждать продолжения"},
+			"hashtags":     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"image_prompts": map[string]interface{}{
+				"type": "array", "items": map[string]interface{}{"type": "string"},
+				"description": "описания картинок на английском",
+			},
+			"publish_time": map[string]interface{}{"type": "string", "description": "время публикации ЧЧ:ММ"},
+		},
+	},
+}
+
+// structuredInstruction объясняет модели поля ответа.
+func structuredInstruction(persona domain.Persona) string {
+	images := "image_prompts — пустой массив."
+	if persona.ImageCount > 0 {
+		images = fmt.Sprintf("image_prompts — ровно %d описаний картинок к посту на английском, по одному-два предложения, "+
+			"без текста на картинке и имен реальных людей.", persona.ImageCount)
+	}
+	return "Ответь строго JSON-объектом без пояснений и блоков кода. Поля: " +
+		"hook — первая фраза-приманка; body — основной текст; closing_hook — финальный крючок; " +
+		fmt.Sprintf("hashtags — от 1 до %d хэштегов со знаком #; ", maxHashtags) + images +
+		" publish_time — лучшее время публикации этого поста в формате ЧЧ:ММ."
+}
+
+// GenerateStructured просит модель пост в формате json и проверяет ответ. Ответ,
+// который не разбирается или не проходит проверку, модель исправляет сама, а
+// сбой запроса повторяется, пока не кончатся structuredAttempts попыток. Время
+// публикации — ближайшее предложенное ЧЧ:ММ в часовом поясе loc.
+func (u *GenerateUsecase) GenerateStructured(persona domain.Persona, topic string, loc *time.Location) (GeneratedPost, error) {
+	if topic == "" {
+		return GeneratedPost{}, errors.New("тема не может быть пустой")
+	}
+	req := u.topicRequest(persona, topic)
+	req.System = strings.TrimSpace(req.System + "\n\n" + structuredInstruction(persona))
+	req.Schema = structuredSchema
+	// Неразборчивый ответ не должен попасть в кэш и повторяться весь срок хранения
+	req.Accept = func(answer string) error {
+		_, err := parseStructuredPost(answer, persona.ImageCount)
+		return err
+	}
+	// Части поста и описания картинок длиннее свободного текста
+	req.MaxTokens += 120 * persona.ImageCount
+
+	prompt := req.Prompt
+	gen := u
+	var err error
+	for attempt := 1; attempt <= structuredAttempts; attempt++ {
+		// Квота не восстановится от повтора
+		if err := u.allow(domain.UsageText); err != nil {
+			return GeneratedPost{}, err
+		}
+		answer, genErr := gen.generateText(req)
+		if genErr != nil {
+			err = fmt.Errorf("ошибка генерации текста: %w", genErr)
+			log.Printf("Попытка %d из %d получить пост в формате json: %v", attempt, structuredAttempts, err)
+			continue
+		}
+		var post StructuredPost
+		if post, err = parseStructuredPost(answer, persona.ImageCount); err == nil {
+			return GeneratedPost{
+				Text:         post.Text(),
+				ImagePrompts: withImageStyle(persona, post.ImagePrompts),
+				PublishAt:    nextPublishTime(post.PublishTime, time.Now().In(loc)),
+			}, nil
+		}
+		// Исправление того же ответа не должно браться из кэша. Исходная
+		// просьба остается в запросе, чтобы исправленный пост не ушел от темы
+		gen = u.Fresh()
+		req.Prompt = fmt.Sprintf("%s\n\nТвой ответ:\n%s\n\nОн не подошел: %v. Исправь его и пришли JSON-объект целиком.", prompt, answer, err)
+	}
+	return GeneratedPost{}, fmt.Errorf("модель не прислала корректный JSON за %d попыток: %w", structuredAttempts, err)
+}
+
+// parseStructuredPost разбирает и проверяет ответ модели. Объект может быть
+// обернут в блок кода или пояснения — берется текст между первой { и последней }.
+// Хэштеги приводятся к виду #слово, лишние описания картинок отбрасываются.
+func parseStructuredPost(answer string, imageCount int) (StructuredPost, error) {
+	start, end := strings.Index(answer, "{"), strings.LastIndex(answer, "}")
+	if start < 0 || end <= start {
+		return StructuredPost{}, errors.New("в ответе нет JSON-объекта")
+	}
+	var post StructuredPost
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &post); err != nil {
+		return StructuredPost{}, fmt.Errorf("JSON не разбирается: %v", err)
+	}
+
+	post.Hook = strings.TrimSpace(post.Hook)
+	post.Body = strings.TrimSpace(post.Body)
+	post.ClosingHook = strings.TrimSpace(post.ClosingHook)
+	switch {
+	case post.Hook == "":
+		return StructuredPost{}, errors.New("поле hook пустое")
+	case post.Body == "":
+		return StructuredPost{}, errors.New("поле body пустое")
+	case post.ClosingHook == "":
+		return StructuredPost{}, errors.New("поле closing_hook пустое")
+	}
+
+	var hashtags []string
+	for _, tag := range post.Hashtags {
+		tag = strings.Join(strings.Fields(strings.TrimLeft(strings.TrimSpace(tag), "#")), "_")
+		if tag != "" {
+			hashtags = append(hashtags, "#"+tag)
+		}
+	}
+	if len(hashtags) == 0 {
+		return StructuredPost{}, errors.New("в hashtags нет ни одного хэштега")
+	}
+	if len(hashtags) > maxHashtags {
+		hashtags = hashtags[:maxHashtags]
+	}
+	post.Hashtags = hashtags
+
+	post.ImagePrompts = nonEmpty(post.ImagePrompts)
+	if len(post.ImagePrompts) < imageCount {
+		return StructuredPost{}, fmt.Errorf("в image_prompts %d описаний, а нужно %d", len(post.ImagePrompts), imageCount)
+	}
+	post.ImagePrompts = post.ImagePrompts[:imageCount]
+
+	post.PublishTime = strings.TrimSpace(post.PublishTime)
+	if _, err := time.Parse("15:04", post.PublishTime); err != nil {
+		return StructuredPost{}, fmt.Errorf("publish_time «%s» не в формате ЧЧ:ММ", post.PublishTime)
+	}
+	return post, nil
+}
+
+// nextPublishTime возвращает ближайший момент после now с временем суток hhmm.
+// Слишком близкое время переносится на следующий день.
+func nextPublishTime(hhmm string, now time.Time) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return time.Time{}
+	}
+	at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if at.Before(now.Add(minScheduleLead)) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// WritePost пишет пост по теме голосом персоны. Персона в формате json получает
+// части поста, описания картинок и время публикации одним ответом; иначе текст
+// отдается в onProgress по мере ответа модели, если onProgress задан.
+func (u *GenerateUsecase) WritePost(persona domain.Persona, topic string, loc *time.Location, onProgress func(text string)) (GeneratedPost, error) {
+	if persona.Structured {
+		post, err := u.GenerateStructured(persona, topic, loc)
+		if err == nil && onProgress != nil {
+			onProgress(post.Text)
+		}
+		return post, err
+	}
+
+	var text string
+	var err error
+	if onProgress != nil {
+		text, err = u.StreamFromTopic(persona, topic, onProgress)
+	} else {
+		text, err = u.GenerateFromTopic(persona, topic)
+	}
+	if err != nil {
+		return GeneratedPost{}, fmt.Errorf("ошибка генерации текста: %w", err)
+	}
+	return GeneratedPost{Text: text}, nil
+}
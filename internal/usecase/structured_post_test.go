@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const validPost = `{
+	"hook": "Знаете, почему кошки спят по 16 часов?",
+	"body": "Они экономят силы для охоты.",
+	"closing_hook": "А завтра расскажу, зачем они мурлычут.",
+	"hashtags": ["#кошки", "сон кошек", "  "],
+	"image_prompts": ["спящая кошка на подоконнике", "", "кошка на охоте"],
+	"publish_time": "18:30"
+}`
+
+func TestParseStructuredPost(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+	}{
+		{"plain", validPost},
+		{"fenced", "```json\n" + validPost + "\n```"},
+		{"with prose", "Вот пост:\n" + validPost + "\nГотово!"},
+		{"extra field", strings.Replace(validPost, `"hook"`, `"mood": "веселый", "hook"`, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post, err := parseStructuredPost(tt.answer, 2)
+			if err != nil {
+				t.Fatalf("parseStructuredPost: %v", err)
+			}
+			if want := []string{"#кошки", "#сон_кошек"}; !reflect.DeepEqual(post.Hashtags, want) {
+				t.Errorf("Hashtags = %q, want %q", post.Hashtags, want)
+			}
+			if want := []string{"спящая кошка на подоконнике", "кошка на охоте"}; !reflect.DeepEqual(post.ImagePrompts, want) {
+				t.Errorf("ImagePrompts = %q, want %q", post.ImagePrompts, want)
+			}
+			if !strings.HasPrefix(post.Text(), "Знаете") || !strings.HasSuffix(post.Text(), "#кошки #сон_кошек") {
+				t.Errorf("Text() = %q", post.Text())
+			}
+		})
+	}
+}
+
+func TestParseStructuredPostErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		answer     string
+		imageCount int
+		want       string
+	}{
+		{"no json", "Не могу написать пост", 2, "нет JSON"},
+		{"broken json", `{"hook": "a",}`, 2, "не разбирается"},
+		{"missing hook", strings.Replace(validPost, `"hook"`, `"title"`, 1), 2, "hook"},
+		{"empty body", strings.Replace(validPost, `"Они экономят силы для охоты."`, `"  "`, 1), 2, "body"},
+		{"missing closing hook", strings.Replace(validPost, `"closing_hook"`, `"ending"`, 1), 2, "closing_hook"},
+		{"zero hashtags", strings.Replace(validPost, `["#кошки", "сон кошек", "  "]`, `[]`, 1), 2, "hashtags"},
+		{"blank hashtags", strings.Replace(validPost, `["#кошки", "сон кошек", "  "]`, `["#", " "]`, 1), 2, "hashtags"},
+		{"too few image prompts", validPost, 3, "image_prompts"},
+		{"bad publish time", strings.Replace(validPost, `"18:30"`, `"вечером"`, 1), 2, "publish_time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseStructuredPost(tt.answer, tt.imageCount)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseStructuredPost error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextPublishTime(t *testing.T) {
+	now := time.Date(2025, time.August, 13, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		hhmm string
+		want time.Time
+	}{
+		{"20:00", time.Date(2025, time.August, 13, 20, 0, 0, 0, time.UTC)},
+		{"18:05", time.Date(2025, time.August, 14, 18, 5, 0, 0, time.UTC)},
+		{"09:00", time.Date(2025, time.August, 14, 9, 0, 0, 0, time.UTC)},
+		{"25:00", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := nextPublishTime(tt.hhmm, now); !got.Equal(tt.want) {
+			t.Errorf("nextPublishTime(%q) = %s, want %s", tt.hhmm, got, tt.want)
+		}
+	}
+}
@@ -6,54 +6,120 @@ import (
 	"lady/internal/domain"
 	"lady/internal/gpt"
 	"lady/internal/repository"
+	"lady/internal/textlimit"
+	"log"
 	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	maxPublishAttempts = 5
+	baseRetryDelay     = time.Minute
+	maxRetryDelay      = 30 * time.Minute
+	condenseAttempts   = 2
+	// pendingScheduleTTL — сколько бот ждет дату публикации, прежде чем снова
+	// считать сообщения темами
+	pendingScheduleTTL = 30 * time.Minute
+	// MaxCandidates ограничивает число текстов, генерируемых за один раз.
+	MaxCandidates = 5
+)
+
 // TopicUsecase управляет темами и их состоянием.
+// Черновики, очередь публикации и состояние диалога хранятся в репозитории,
+// поэтому переживают перезапуск бота.
 type TopicUsecase struct {
-	repo         *repository.TopicRepository
-	pendingEdits map[int64]struct {
-		Text      string
-		MessageID int
-	}
-	pendingPosts map[int64]struct {
-		Text      string
-		Img1      string // URL первой фотографии
-		Img2      string // URL второй фотографии
-		PublishAt time.Time
-	}
-	pendingSchedule map[int64]bool
-	mu              sync.RWMutex
+	repo *repository.TopicRepository
 }
 
-// GenerateUsecase управляет генерацией текстов.
+// GenerateUsecase управляет генерацией текстов и картинок. Расход каждого
+// запроса записывается на редактора и канал, заданные через For.
 type GenerateUsecase struct {
-	gpt *gpt.GroqClient
+	text      gpt.TextGenerator
+	images    gpt.ImageGenerator
+	usage     *UsageUsecase // nil — расход не учитывается
+	userID    int64
+	channelID int64
+	fresh     bool // не брать ответы из кэша
+	// seriesContext — пересказ предыдущих постов серии, см. InSeries
+	seriesContext string
 }
 
 // NewTopicUsecase создает новый экземпляр TopicUsecase.
 func NewTopicUsecase(r *repository.TopicRepository) *TopicUsecase {
-	return &TopicUsecase{
-		repo: r,
-		pendingEdits: make(map[int64]struct {
-			Text      string
-			MessageID int
-		}),
-		pendingPosts: make(map[int64]struct {
-			Text      string
-			Img1      string
-			Img2      string
-			PublishAt time.Time
-		}),
-		pendingSchedule: make(map[int64]bool),
-	}
+	return &TopicUsecase{repo: r}
 }
 
 // NewGenerateUsecase создает новый экземпляр GenerateUsecase.
-func NewGenerateUsecase(gptClient *gpt.GroqClient) *GenerateUsecase {
-	return &GenerateUsecase{gpt: gptClient}
+func NewGenerateUsecase(text gpt.TextGenerator, images gpt.ImageGenerator, usage *UsageUsecase) *GenerateUsecase {
+	return &GenerateUsecase{text: text, images: images, usage: usage}
+}
+
+// For возвращает генератор, который проверяет квоты редактора userID и
+// записывает расход на него и на канал channelID.
+func (u *GenerateUsecase) For(userID, channelID int64) *GenerateUsecase {
+	bound := *u
+	bound.userID = userID
+	bound.channelID = channelID
+	return &bound
+}
+
+// Fresh возвращает генератор, который не берет ответы из кэша, — для
+// перегенерации и вариантов, где одинаковый ответ бесполезен.
+func (u *GenerateUsecase) Fresh() *GenerateUsecase {
+	fresh := *u
+	fresh.fresh = true
+	return &fresh
+}
+
+// allow проверяет квоту редактора перед запросом вида kind.
+func (u *GenerateUsecase) allow(kind domain.UsageKind) error {
+	if u.usage == nil {
+		return nil
+	}
+	return u.usage.Check(u.userID, kind)
+}
+
+// allowImages проверяет, что в квоте редактора есть место для n картинок.
+func (u *GenerateUsecase) allowImages(n int) error {
+	if u.usage == nil {
+		return nil
+	}
+	return u.usage.CheckImages(u.userID, n)
+}
+
+// record записывает расход запроса на редактора и канал.
+func (u *GenerateUsecase) record(usage domain.Usage) {
+	if u.usage == nil {
+		return
+	}
+	usage.UserID = u.userID
+	usage.ChannelID = u.channelID
+	u.usage.Record(usage)
+}
+
+// recordTokens записывает расход текстового запроса; ответ из кэша ничего не стоит.
+func (u *GenerateUsecase) recordTokens(tokens gpt.TokenUsage) {
+	if tokens.Cached {
+		return
+	}
+	u.record(domain.Usage{Kind: domain.UsageText, PromptTokens: tokens.PromptTokens, CompletionTokens: tokens.CompletionTokens})
+}
+
+// generateText запрашивает текст у модели с учетом квоты и расхода.
+func (u *GenerateUsecase) generateText(req gpt.TextRequest) (string, error) {
+	if err := u.allow(domain.UsageText); err != nil {
+		return "", err
+	}
+	var tokens gpt.TokenUsage
+	req.Usage = &tokens
+	req.NoCache = u.fresh
+	text, err := u.text.GenerateText(req)
+	if err != nil {
+		return "", err
+	}
+	u.recordTokens(tokens)
+	return text, nil
 }
 
 // AddTopic добавляет новую тему.
@@ -70,144 +136,520 @@ func (u *TopicUsecase) ListTopics() ([]domain.Topic, error) {
 	return u.repo.List()
 }
 
-// GenerateFromTopic генерирует текст на основе темы.
-func (u *GenerateUsecase) GenerateFromTopic(topic string) (string, error) {
+// FindTopic ищет сохраненную тему по названию.
+func (u *TopicUsecase) FindTopic(title string) (domain.Topic, error) {
+	return u.repo.FindByTitle(strings.TrimSpace(title))
+}
+
+// topicRequest собирает запрос к модели на текст по теме голосом персоны.
+// Пост серии получает в запрос пересказ предыдущих постов.
+func (u *GenerateUsecase) topicRequest(persona domain.Persona, topic string) gpt.TextRequest {
+	system, prompt := persona.Render(topic, time.Now())
+	if u.seriesContext != "" {
+		prompt += "\n\n" + u.seriesContext
+	}
+	return gpt.TextRequest{
+		System: system,
+		Prompt: prompt,
+		// Русский текст занимает примерно токен на пару символов
+		MaxTokens:   max(800, persona.Length),
+		Temperature: 0.8,
+	}
+}
+
+// GenerateFromTopic генерирует свободный текст на основе темы голосом персоны.
+// Формат персоны не учитывается — для него есть WritePost.
+func (u *GenerateUsecase) GenerateFromTopic(persona domain.Persona, topic string) (string, error) {
+	if topic == "" {
+		return "", errors.New("тема не может быть пустой")
+	}
+	return u.generateText(u.topicRequest(persona, topic))
+}
+
+// StreamFromTopic генерирует текст как GenerateFromTopic, но отдает его по мере
+// ответа модели: onProgress получает весь текст, накопленный к этому моменту.
+// Если провайдер не умеет отвечать потоком, onProgress вызывается один раз.
+func (u *GenerateUsecase) StreamFromTopic(persona domain.Persona, topic string, onProgress func(text string)) (string, error) {
 	if topic == "" {
 		return "", errors.New("тема не может быть пустой")
 	}
-	prompt := fmt.Sprintf(
-		"Ты — креативный копирайтер, который пишет короткие чувственные тексты для постов в Телеграм. "+
-			"Стиль — интимный, будто автор говорит лично с читателем, с лёгкой игрой и флиртом, загадочностью и недосказанностью. "+
-			"Используй метафоры, сенсорные детали (взгляд, прикосновение, звук), эмоциональные контрасты. "+
-			"Тексты должны быть живыми, с короткими и длинными фразами, создавая ощущение разговора «один на один». "+
-			"В начале всегда интригующая фраза-приманка, в середине — эмоциональный пик с игрой образов, в конце — крючок, который заставляет ждать продолжения. "+
-			"Эмодзи используй аккуратно, чтобы усиливать настроение, а не просто вставлять. "+
-			"Избегай прямых объяснений — передавай чувства через образы и действия. "+
-			"Пиши от первого лица, от женского лица, с уверенностью, мягкой провокацией и тайной. "+
-			"Сгенерируй текст с длинной 1024 символов в таком стиле по теме: %s", topic)
-	return u.gpt.GenerateText(prompt)
-}
-
-// SavePendingEdit сохраняет данные для редактирования.
-func (u *TopicUsecase) SavePendingEdit(chatID int64, messageID int, text string) error {
-	u.mu.Lock()
-	defer u.mu.Unlock()
+	streamer, ok := u.text.(gpt.TextStreamer)
+	if !ok {
+		text, err := u.generateText(u.topicRequest(persona, topic))
+		if err == nil {
+			onProgress(text)
+		}
+		return text, err
+	}
+	if err := u.allow(domain.UsageText); err != nil {
+		return "", err
+	}
+
+	req := u.topicRequest(persona, topic)
+	var tokens gpt.TokenUsage
+	req.Usage = &tokens
+	req.NoCache = u.fresh
+	var text strings.Builder
+	res, err := streamer.StreamText(req, func(delta string) {
+		text.WriteString(delta)
+		onProgress(text.String())
+	})
+	if err != nil {
+		return "", err
+	}
+	u.recordTokens(tokens)
+	return res, nil
+}
+
+// GenerateCandidates параллельно пишет n вариантов поста по теме, как WritePost,
+// и укладывает каждый текст в подпись. Неудачные генерации пропускаются; ошибка
+// возвращается, только если не получилось ни одного варианта. Кэш не
+// используется, иначе все варианты совпали бы.
+func (u *GenerateUsecase) GenerateCandidates(persona domain.Persona, topic string, n int, loc *time.Location) ([]GeneratedPost, error) {
+	if n < 1 || n > MaxCandidates {
+		return nil, fmt.Errorf("число вариантов должно быть от 1 до %d", MaxCandidates)
+	}
+	u = u.Fresh()
+	posts := make([]GeneratedPost, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			post, err := u.WritePost(persona, topic, loc, nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if condensed, err := u.Condense(post.Text, textlimit.Caption); err == nil {
+				post.Text = condensed
+			} else {
+				// Не страшно: при публикации остаток уйдет ответом
+				log.Printf("Ошибка сокращения варианта %d: %v", i+1, err)
+			}
+			posts[i] = post
+		}(i)
+	}
+	wg.Wait()
+
+	var res []GeneratedPost
+	for i, post := range posts {
+		if errs[i] != nil {
+			log.Printf("Ошибка генерации варианта %d по теме %q: %v", i+1, topic, errs[i])
+			continue
+		}
+		res = append(res, post)
+	}
+	if len(res) == 0 {
+		return nil, errs[0]
+	}
+	return res, nil
+}
+
+// Condense просит модель сократить текст до limit символов (в единицах UTF-16,
+// как считает Telegram). Если модель не уложилась за condenseAttempts попыток,
+// возвращает ошибку — тогда остаток уйдет отдельным сообщением.
+func (u *GenerateUsecase) Condense(text string, limit int) (string, error) {
+	if textlimit.Len(text) <= limit {
+		return text, nil
+	}
+	target := limit
+	for attempt := 1; attempt <= condenseAttempts; attempt++ {
+		// Модели плохо считают символы, поэтому просим с запасом
+		target = target * 9 / 10
+		condensed, err := u.generateText(gpt.TextRequest{
+			System: "Ты редактор постов для Телеграм. Отвечай только текстом поста, без пояснений.",
+			Prompt: fmt.Sprintf("Сократи пост до %d символов. Сохрани стиль, голос автора, эмодзи, "+
+				"первую фразу-приманку и финальный крючок. Не обрывай предложения.\n\n%s", target, text),
+			MaxTokens:   max(800, limit),
+			Temperature: 0.3,
+		})
+		if err != nil {
+			return "", fmt.Errorf("ошибка сокращения текста: %w", err)
+		}
+		condensed = strings.TrimSpace(condensed)
+		if condensed != "" && textlimit.Len(condensed) <= limit {
+			return condensed, nil
+		}
+	}
+	return "", fmt.Errorf("модель не сократила текст до %d символов", limit)
+}
+
+// GenerateImage генерирует картинку по описанию и возвращает ее URL.
+func (u *GenerateUsecase) GenerateImage(prompt string) (string, error) {
+	if prompt == "" {
+		return "", errors.New("описание картинки не может быть пустым")
+	}
+	if err := u.allow(domain.UsageImage); err != nil {
+		return "", err
+	}
+	return u.drawImage(prompt)
+}
+
+// drawImage рисует картинку и записывает расход, не проверяя квоту.
+func (u *GenerateUsecase) drawImage(prompt string) (string, error) {
+	if prompt == "" {
+		return "", errors.New("описание картинки не может быть пустым")
+	}
+	var cached bool
+	url, err := u.images.GenerateImage(gpt.ImageRequest{Prompt: prompt, NoCache: u.fresh, Cached: &cached})
+	if err != nil {
+		return "", err
+	}
+	if !cached {
+		u.record(domain.Usage{Kind: domain.UsageImage, Images: 1})
+	}
+	return url, nil
+}
+
+// CreateDraft сохраняет новый черновик и возвращает его с присвоенным ID.
+func (u *TopicUsecase) CreateDraft(authorID, channelID, topicID, seriesID int64, text string, images []domain.PostImage) (domain.Post, error) {
 	if text == "" {
-		return errors.New("текст для редактирования не может быть пустым")
+		return domain.Post{}, errors.New("текст поста не может быть пустым")
 	}
-	u.pendingEdits[chatID] = struct {
-		Text      string
-		MessageID int
-	}{Text: text, MessageID: messageID}
-	return nil
+	p := domain.Post{
+		AuthorID:  authorID,
+		ChannelID: channelID,
+		TopicID:   topicID,
+		SeriesID:  seriesID,
+		Status:    domain.PostDraft,
+		Text:      text,
+		Images:    images,
+	}
+	id, err := u.repo.CreatePost(p)
+	if err != nil {
+		return domain.Post{}, err
+	}
+	return u.repo.GetPost(id)
 }
 
-// GetPendingEdit получает данные для редактирования.
-func (u *TopicUsecase) GetPendingEdit(chatID int64) (string, int, error) {
-	u.mu.RLock()
-	defer u.mu.RUnlock()
-	edit, exists := u.pendingEdits[chatID]
-	if !exists {
-		return "", 0, errors.New("нет данных для редактирования")
+// CreateCandidates сохраняет черновик, у которого каждый сгенерированный пост —
+// отдельный вариант вместе с предложенными описаниями картинок и временем, и
+// возвращает его с вариантами. Выбранным остается первый вариант, пока
+// редактор не выберет другой.
+func (u *TopicUsecase) CreateCandidates(authorID, channelID, topicID, seriesID int64, candidates []GeneratedPost) (domain.Post, []domain.PostVariant, error) {
+	if len(candidates) == 0 {
+		return domain.Post{}, nil, errors.New("нет вариантов текста")
 	}
-	return edit.Text, edit.MessageID, nil
+	p, err := u.CreateDraft(authorID, channelID, topicID, seriesID, candidates[0].Text, nil)
+	if err != nil {
+		return domain.Post{}, nil, err
+	}
+	for i, c := range candidates {
+		variantID := p.VariantID
+		if i > 0 {
+			if variantID, err = u.repo.AddVariant(p.ID, c.Text); err != nil {
+				return domain.Post{}, nil, err
+			}
+		}
+		if len(c.ImagePrompts) > 0 || !c.PublishAt.IsZero() {
+			if err := u.repo.SetVariantSuggestions(variantID, c.ImagePrompts, c.PublishAt); err != nil {
+				return domain.Post{}, nil, err
+			}
+		}
+	}
+	if err := u.repo.SelectVariant(p.ID, p.VariantID); err != nil {
+		return domain.Post{}, nil, err
+	}
+	variants, err := u.repo.ListVariants(p.ID)
+	if err != nil {
+		return domain.Post{}, nil, err
+	}
+	p, err = u.repo.GetPost(p.ID)
+	return p, variants, err
 }
 
-// ClearPendingEdit очищает данные редактирования.
-func (u *TopicUsecase) ClearPendingEdit(chatID int64) error {
-	u.mu.Lock()
-	defer u.mu.Unlock()
-	if _, exists := u.pendingEdits[chatID]; !exists {
-		return errors.New("нет данных для очистки")
+// SetPostImages заменяет картинки поста, пока тот не ушел в канал.
+func (u *TopicUsecase) SetPostImages(postID int64, images []domain.PostImage) (domain.Post, error) {
+	if _, err := u.editableImages(postID); err != nil {
+		return domain.Post{}, err
 	}
-	delete(u.pendingEdits, chatID)
-	return nil
+	if len(images) > domain.MaxPostImages {
+		return domain.Post{}, fmt.Errorf("в посте может быть не больше %d картинок", domain.MaxPostImages)
+	}
+	if err := u.repo.ReplacePostImages(postID, images); err != nil {
+		return domain.Post{}, err
+	}
+	return u.repo.GetPost(postID)
+}
+
+// GetPost возвращает пост, если он принадлежит автору.
+func (u *TopicUsecase) GetPost(authorID, postID int64) (domain.Post, error) {
+	p, err := u.repo.GetPost(postID)
+	if err != nil || p.AuthorID != authorID {
+		return domain.Post{}, errors.New("пост не найден")
+	}
+	return p, nil
 }
 
-// SavePendingPost сохраняет пост для отложенной публикации с указанием времени и фотографий.
-func (u *TopicUsecase) SavePendingPost(chatID int64, text, img1, img2 string, publishAt time.Time) error {
-	u.mu.Lock()
-	defer u.mu.Unlock()
+// LatestDraft возвращает последний черновик автора.
+func (u *TopicUsecase) LatestDraft(authorID int64) (domain.Post, error) {
+	p, err := u.repo.LatestDraft(authorID)
+	if err != nil {
+		return domain.Post{}, errors.New("нет отложенного поста")
+	}
+	return p, nil
+}
+
+// UpdatePostText заменяет текст поста, пока тот не ушел в канал.
+func (u *TopicUsecase) UpdatePostText(postID int64, text string) error {
 	if text == "" {
 		return errors.New("текст поста не может быть пустым")
 	}
-	if !publishAt.IsZero() {
-		// Допускаем планирование на ближайшие 2 минуты
-		if publishAt.Before(time.Now().Add(-2 * time.Minute)) {
-			return fmt.Errorf("время публикации (%s) не может быть в прошлом (текущее время: %s)", publishAt.Format("02.01.2006 15:04"), time.Now().Format("02.01.2006 15:04"))
-		}
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return errors.New("пост не найден")
 	}
-	u.pendingPosts[chatID] = struct {
-		Text, Img1, Img2 string
-		PublishAt        time.Time
-	}{Text: text, Img1: img1, Img2: img2, PublishAt: publishAt}
-	return nil
+	if !p.Editable() {
+		return fmt.Errorf("пост уже %s, текст менять поздно", p.Status.Title())
+	}
+	return u.repo.UpdatePostText(postID, text)
+}
+
+// PostTopic возвращает тему, из которой сгенерирован пост.
+func (u *TopicUsecase) PostTopic(post domain.Post) (domain.Topic, error) {
+	if post.TopicID == 0 {
+		return domain.Topic{}, errors.New("пост создан без темы")
+	}
+	return u.repo.GetTopic(post.TopicID)
+}
+
+// AddVariant сохраняет новый вариант текста поста и делает его выбранным.
+func (u *TopicUsecase) AddVariant(postID int64, text string) (domain.Post, error) {
+	if text == "" {
+		return domain.Post{}, errors.New("текст поста не может быть пустым")
+	}
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return domain.Post{}, errors.New("пост не найден")
+	}
+	if !p.Editable() {
+		return domain.Post{}, fmt.Errorf("пост уже %s, текст менять поздно", p.Status.Title())
+	}
+	if _, err := u.repo.AddVariant(postID, text); err != nil {
+		return domain.Post{}, err
+	}
+	return u.repo.GetPost(postID)
 }
 
-// GetPendingPost получает отложенный пост.
-func (u *TopicUsecase) GetPendingPost(chatID int64) (string, string, string, time.Time, error) {
-	u.mu.RLock()
-	defer u.mu.RUnlock()
-	post, exists := u.pendingPosts[chatID]
-	if !exists {
-		return "", "", "", time.Time{}, errors.New("нет отложенного поста")
+// SelectVariant выбирает вариант текста для публикации.
+func (u *TopicUsecase) SelectVariant(postID, variantID int64) (domain.Post, error) {
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return domain.Post{}, errors.New("пост не найден")
+	}
+	if !p.Editable() {
+		return domain.Post{}, fmt.Errorf("пост уже %s, текст менять поздно", p.Status.Title())
 	}
-	return post.Text, post.Img1, post.Img2, post.PublishAt, nil
+	if err := u.repo.SelectVariant(postID, variantID); err != nil {
+		return domain.Post{}, err
+	}
+	return u.repo.GetPost(postID)
+}
+
+// ListVariants возвращает варианты текста поста.
+func (u *TopicUsecase) ListVariants(postID int64) ([]domain.PostVariant, error) {
+	return u.repo.ListVariants(postID)
 }
 
-// ClearPendingPost очищает отложенный пост.
-func (u *TopicUsecase) ClearPendingPost(chatID int64) error {
-	u.mu.Lock()
-	defer u.mu.Unlock()
-	if _, exists := u.pendingPosts[chatID]; !exists {
-		return errors.New("нет отложенного поста для очистки")
+// SetPostChannel выбирает канал публикации поста, пока тот не ушел в канал.
+// Канал должен быть привязан к боту.
+func (u *TopicUsecase) SetPostChannel(postID, channelID int64) error {
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return errors.New("пост не найден")
+	}
+	if !p.Editable() {
+		return fmt.Errorf("пост уже %s, канал менять поздно", p.Status.Title())
+	}
+	if err := u.repo.UpdatePostChannel(postID, channelID); err != nil {
+		return fmt.Errorf("канал %d не привязан к боту", channelID)
 	}
-	delete(u.pendingPosts, chatID)
 	return nil
 }
 
-// GetScheduledPosts возвращает посты, готовые к публикации, и удаляет их из pendingPosts.
-func (u *TopicUsecase) GetScheduledPosts() map[int64]struct {
-	Text, Img1, Img2 string
-	PublishAt        time.Time
-} {
-	u.mu.Lock()
-	defer u.mu.Unlock()
-	posts := make(map[int64]struct {
-		Text, Img1, Img2 string
-		PublishAt        time.Time
-	})
-	for chatID, post := range u.pendingPosts {
-		if !post.PublishAt.IsZero() && (post.PublishAt.Before(time.Now()) || post.PublishAt.Equal(time.Now())) {
-			posts[chatID] = post
-			delete(u.pendingPosts, chatID) // Удаляем пост, чтобы он не публиковался повторно
+// SchedulePost ставит пост в очередь публикации.
+func (u *TopicUsecase) SchedulePost(postID int64, publishAt time.Time) error {
+	if publishAt.IsZero() {
+		return errors.New("не указано время публикации")
+	}
+	// Допускаем планирование на ближайшие 2 минуты
+	if publishAt.Before(time.Now().Add(-2 * time.Minute)) {
+		return fmt.Errorf("время публикации (%s) не может быть в прошлом (текущее время: %s)", publishAt.Format("02.01.2006 15:04"), time.Now().In(publishAt.Location()).Format("02.01.2006 15:04"))
+	}
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return errors.New("пост не найден")
+	}
+	if !p.CanTransition(domain.PostScheduled) {
+		return domain.ErrInvalidTransition{PostID: p.ID, From: p.Status, To: domain.PostScheduled}
+	}
+	return u.repo.SchedulePost(postID, p.Status, publishAt)
+}
+
+// transition переводит пост в новый статус, проверяя жизненный цикл.
+func (u *TopicUsecase) transition(postID int64, to domain.PostStatus) error {
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return errors.New("пост не найден")
+	}
+	if !p.CanTransition(to) {
+		return domain.ErrInvalidTransition{PostID: p.ID, From: p.Status, To: to}
+	}
+	return u.repo.UpdatePostStatus(postID, p.Status, to)
+}
+
+// CancelPost отменяет пост и снимает его с очереди.
+func (u *TopicUsecase) CancelPost(postID int64) error {
+	return u.transition(postID, domain.PostCancelled)
+}
+
+// StartPublishing помечает пост как отправляемый в канал прямо сейчас.
+func (u *TopicUsecase) StartPublishing(postID int64) error {
+	return u.transition(postID, domain.PostPublishing)
+}
+
+// MarkPublished отмечает успешную публикацию.
+func (u *TopicUsecase) MarkPublished(postID int64) error {
+	return u.transition(postID, domain.PostPublished)
+}
+
+// MarkFailed отмечает неудачную публикацию без повторов — например, когда
+// редактор публиковал вручную и сам решит, что делать дальше.
+func (u *TopicUsecase) MarkFailed(postID int64, cause error) error {
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return errors.New("пост не найден")
+	}
+	return u.repo.RecordPublishFailure(postID, domain.PostFailed, p.Attempts+1, cause.Error(), time.Time{})
+}
+
+// PublishFailure — итог неудачной попытки публикации запланированного поста.
+type PublishFailure struct {
+	Attempts      int
+	Final         bool      // попытки исчерпаны, пост в статусе failed
+	NextAttemptAt time.Time // когда будет следующий повтор, если Final == false
+}
+
+// RecordPublishFailure фиксирует ошибку публикации запланированного поста.
+// Пока попытки не исчерпаны, пост возвращается в очередь с экспоненциальной
+// задержкой; после maxPublishAttempts он переходит в статус failed.
+func (u *TopicUsecase) RecordPublishFailure(postID int64, cause error) (PublishFailure, error) {
+	p, err := u.repo.GetPost(postID)
+	if err != nil {
+		return PublishFailure{}, errors.New("пост не найден")
+	}
+	res := PublishFailure{Attempts: p.Attempts + 1}
+	if res.Attempts >= maxPublishAttempts {
+		res.Final = true
+		return res, u.repo.RecordPublishFailure(postID, domain.PostFailed, res.Attempts, cause.Error(), time.Time{})
+	}
+	res.NextAttemptAt = time.Now().Add(retryDelay(res.Attempts))
+	return res, u.repo.RecordPublishFailure(postID, domain.PostScheduled, res.Attempts, cause.Error(), res.NextAttemptAt)
+}
+
+// retryDelay возвращает задержку перед повтором после attempt неудачных попыток:
+// 1, 2, 4, 8... минут, но не больше maxRetryDelay.
+func retryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		return baseRetryDelay
+	}
+	delay := baseRetryDelay << (attempt - 1)
+	if delay > maxRetryDelay || delay <= 0 {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// FailInterruptedPosts переводит в failed посты, застрявшие в статусе publishing
+// после аварийной остановки бота. Дошел ли такой пост до канала, неизвестно,
+// поэтому решение о повторе остается за автором.
+func (u *TopicUsecase) FailInterruptedPosts() []domain.Post {
+	posts, err := u.repo.ListByStatus(domain.PostPublishing)
+	if err != nil {
+		return nil
+	}
+	var failed []domain.Post
+	for _, p := range posts {
+		if err := u.MarkFailed(p.ID, errors.New("публикация прервана перезапуском бота")); err != nil {
+			continue
 		}
+		failed = append(failed, p)
+	}
+	return failed
+}
+
+// ListQueue возвращает запланированные посты автора в порядке публикации.
+func (u *TopicUsecase) ListQueue(authorID int64) ([]domain.Post, error) {
+	return u.repo.ListQueue(authorID)
+}
+
+// ClaimDuePosts возвращает посты, готовые к публикации, переводя их в статус publishing,
+// чтобы они не публиковались повторно.
+func (u *TopicUsecase) ClaimDuePosts() []domain.Post {
+	posts, err := u.repo.ClaimDuePosts(time.Now())
+	if err != nil {
+		return nil
 	}
 	return posts
 }
 
-// SavePendingSchedule сохраняет состояние ожидания ввода даты.
-func (u *TopicUsecase) SavePendingSchedule(chatID int64) error {
-	u.mu.Lock()
-	defer u.mu.Unlock()
-	u.pendingSchedule[chatID] = true
+// SavePendingEdit запоминает, что следующее сообщение чата — новый текст поста.
+func (u *TopicUsecase) SavePendingEdit(chatID, postID int64, messageID int) error {
+	return u.repo.SavePendingEdit(chatID, postID, messageID)
+}
+
+// GetPendingEdit возвращает пост и сообщение, ожидающие редактирования.
+func (u *TopicUsecase) GetPendingEdit(chatID int64) (int64, int, error) {
+	postID, messageID, err := u.repo.GetPendingEdit(chatID)
+	if err != nil {
+		return 0, 0, errors.New("нет данных для редактирования")
+	}
+	return postID, messageID, nil
+}
+
+// ClearPendingEdit очищает данные редактирования.
+func (u *TopicUsecase) ClearPendingEdit(chatID int64) error {
+	if err := u.repo.ClearPendingEdit(chatID); err != nil {
+		return errors.New("нет данных для очистки")
+	}
 	return nil
 }
 
-// IsPendingSchedule проверяет, ожидается ли ввод даты.
-func (u *TopicUsecase) IsPendingSchedule(chatID int64) bool {
-	u.mu.RLock()
-	defer u.mu.RUnlock()
-	return u.pendingSchedule[chatID]
+// SavePendingSchedule запоминает, что чат вводит дату публикации для поста.
+func (u *TopicUsecase) SavePendingSchedule(chatID, postID int64) error {
+	return u.repo.SavePendingSchedule(chatID, postID)
+}
+
+// GetPendingSchedule возвращает пост, для которого ожидается ввод даты.
+// Ожидание дольше pendingScheduleTTL считается брошенным и очищается.
+func (u *TopicUsecase) GetPendingSchedule(chatID int64) (int64, error) {
+	postID, createdAt, err := u.repo.GetPendingSchedule(chatID)
+	if err != nil {
+		return 0, errors.New("нет состояния ожидания даты")
+	}
+	if time.Since(createdAt) > pendingScheduleTTL {
+		u.repo.ClearPendingSchedule(chatID)
+		return 0, errors.New("нет состояния ожидания даты")
+	}
+	return postID, nil
 }
 
 // ClearPendingSchedule очищает состояние ожидания ввода даты.
 func (u *TopicUsecase) ClearPendingSchedule(chatID int64) error {
-	u.mu.Lock()
-	defer u.mu.Unlock()
-	if _, exists := u.pendingSchedule[chatID]; !exists {
+	if err := u.repo.ClearPendingSchedule(chatID); err != nil {
 		return errors.New("нет состояния ожидания даты")
 	}
-	delete(u.pendingSchedule, chatID)
 	return nil
 }
+
+// ClearPendingInput сбрасывает все ожидания ввода чата: правку текста,
+// AI-правку, описание картинки и дату публикации. Вызывается, когда редактор
+// начинает новый пост, чтобы брошенный диалог не перехватил его сообщения.
+func (u *TopicUsecase) ClearPendingInput(chatID int64) {
+	u.repo.ClearPendingEdit(chatID)
+	u.repo.ClearPendingAIEdit(chatID)
+	u.repo.ClearPendingImagePrompt(chatID)
+	u.repo.ClearPendingSchedule(chatID)
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"lady/internal/domain"
+	"lady/internal/repository"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{5, 16 * time.Minute},
+		{6, maxRetryDelay},
+		{64, maxRetryDelay},
+		{1000, maxRetryDelay},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// newTestDB возвращает пустую базу с примененными миграциями.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := repository.Open(filepath.Join(t.TempDir(), "lady.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := repository.Migrate(db); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	return db
+}
+
+func TestSchedulePostRefusesPublishing(t *testing.T) {
+	u := NewTopicUsecase(repository.NewTopicRepository(newTestDB(t)))
+	post, err := u.CreateDraft(1, 0, 0, 0, "текст поста", nil)
+	if err != nil {
+		t.Fatalf("CreateDraft: %v", err)
+	}
+	if err := u.SchedulePost(post.ID, time.Now()); err != nil {
+		t.Fatalf("SchedulePost: %v", err)
+	}
+	if claimed := u.ClaimDuePosts(); len(claimed) != 1 || claimed[0].ID != post.ID {
+		t.Fatalf("ClaimDuePosts = %v, want post %d", claimed, post.ID)
+	}
+
+	// Планировщик уже отправляет пост: новое время привело бы ко второй публикации
+	err = u.SchedulePost(post.ID, time.Now().Add(time.Hour))
+	var invalid domain.ErrInvalidTransition
+	if !errors.As(err, &invalid) || invalid.From != domain.PostPublishing {
+		t.Fatalf("SchedulePost on a publishing post = %v, want ErrInvalidTransition from publishing", err)
+	}
+	if err := u.MarkPublished(post.ID); err != nil {
+		t.Errorf("MarkPublished after refused reschedule: %v", err)
+	}
+}
+
+func TestSetPostChannelRequiresBoundChannel(t *testing.T) {
+	db := newTestDB(t)
+	u := NewTopicUsecase(repository.NewTopicRepository(db))
+	if err := repository.NewChannelRepository(db).Save(domain.Channel{ID: -100, Title: "Канал", AddedBy: 1}); err != nil {
+		t.Fatalf("Save channel: %v", err)
+	}
+	post, err := u.CreateDraft(1, 0, 0, 0, "текст поста", nil)
+	if err != nil {
+		t.Fatalf("CreateDraft: %v", err)
+	}
+
+	if err := u.SetPostChannel(post.ID, -200); err == nil {
+		t.Error("SetPostChannel accepted a channel that is not bound")
+	}
+	if err := u.SetPostChannel(post.ID, -100); err != nil {
+		t.Fatalf("SetPostChannel to a bound channel: %v", err)
+	}
+	got, err := u.GetPost(1, post.ID)
+	if err != nil {
+		t.Fatalf("GetPost: %v", err)
+	}
+	if got.ChannelID != -100 {
+		t.Errorf("post channel = %d, want -100", got.ChannelID)
+	}
+}
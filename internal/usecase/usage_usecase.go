@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"fmt"
+	"lady/config"
+	"lady/internal/domain"
+	"lady/internal/repository"
+	"log"
+	"sync"
+	"time"
+)
+
+// UsageUsecase считает расход на генерацию, следит за квотами редакторов и
+// предупреждает администратора, когда месячный бюджет почти исчерпан.
+type UsageUsecase struct {
+	repo   *repository.UsageRepository
+	limits config.UsageConfig
+
+	mu          sync.Mutex
+	notify      func(chatID int64, text string)
+	alertedFrom time.Time // начало месяца, о котором администратор уже предупрежден
+}
+
+// NewUsageUsecase создает новый экземпляр UsageUsecase.
+func NewUsageUsecase(r *repository.UsageRepository, limits config.UsageConfig) *UsageUsecase {
+	return &UsageUsecase{repo: r, limits: limits}
+}
+
+// SetNotifier задает, как отправлять предупреждения администратору.
+func (u *UsageUsecase) SetNotifier(notify func(chatID int64, text string)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.notify = notify
+}
+
+// Limits возвращает настроенные квоты и бюджет.
+func (u *UsageUsecase) Limits() config.UsageConfig {
+	return u.limits
+}
+
+// IsAdmin сообщает, получает ли чат предупреждения о бюджете.
+func (u *UsageUsecase) IsAdmin(chatID int64) bool {
+	return u.limits.AdminChatID != 0 && chatID == u.limits.AdminChatID
+}
+
+// dayStart и monthStart — границы периодов квот, по UTC.
+func dayStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func monthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Check возвращает ошибку, если редактор исчерпал дневную или месячную квоту
+// на запросы вида kind.
+func (u *UsageUsecase) Check(userID int64, kind domain.UsageKind) error {
+	return u.check(userID, kind, 1)
+}
+
+// CheckImages возвращает ошибку, если в квоте редактора не осталось места
+// для n картинок.
+func (u *UsageUsecase) CheckImages(userID int64, n int) error {
+	return u.check(userID, domain.UsageImage, n)
+}
+
+// check проверяет квоту на запросы вида kind; images — сколько картинок
+// собираются нарисовать.
+func (u *UsageUsecase) check(userID int64, kind domain.UsageKind, images int) error {
+	now := time.Now()
+	checks := []struct {
+		since  time.Time
+		period string
+		tokens int
+		images int
+	}{
+		{dayStart(now), "дневной", u.limits.DailyTokens, u.limits.DailyImages},
+		{monthStart(now), "месячный", u.limits.MonthlyTokens, u.limits.MonthlyImages},
+	}
+	for _, c := range checks {
+		// Картинки не тратят токены, а текст — картинки: проверяется только своя квота
+		if kind != domain.UsageText {
+			c.tokens = 0
+		}
+		if kind != domain.UsageImage {
+			c.images = 0
+		}
+		if c.tokens == 0 && c.images == 0 {
+			continue
+		}
+		totals, err := u.repo.UserTotals(userID, c.since)
+		if err != nil {
+			// Не блокируем работу из-за сбоя учета
+			log.Printf("Ошибка проверки квоты редактора %d: %v", userID, err)
+			return nil
+		}
+		if c.tokens > 0 && totals.Tokens >= c.tokens {
+			return fmt.Errorf("%s лимит токенов исчерпан (%d из %d), подробнее: /usage", c.period, totals.Tokens, c.tokens)
+		}
+		if c.images > 0 && totals.Images >= c.images {
+			return fmt.Errorf("%s лимит картинок исчерпан (%d из %d), подробнее: /usage", c.period, totals.Images, c.images)
+		}
+		if c.images > 0 && totals.Images+images > c.images {
+			return fmt.Errorf("%s лимит картинок: осталось %d из %d, а нужно %d, подробнее: /usage", c.period, c.images-totals.Images, c.images, images)
+		}
+	}
+	return nil
+}
+
+// Record сохраняет расход, считает его стоимость и проверяет бюджет.
+func (u *UsageUsecase) Record(usage domain.Usage) {
+	usage.Cost = float64(usage.PromptTokens)/1000*u.limits.PromptPrice +
+		float64(usage.CompletionTokens)/1000*u.limits.CompletionPrice +
+		float64(usage.Images)*u.limits.ImagePrice
+	if err := u.repo.Record(usage); err != nil {
+		return
+	}
+	u.checkBudget()
+}
+
+// checkBudget один раз за месяц предупреждает администратора, что расход
+// подошел к порогу бюджета.
+func (u *UsageUsecase) checkBudget() {
+	if u.limits.MonthlyBudget <= 0 || u.limits.AdminChatID == 0 {
+		return
+	}
+	month := monthStart(time.Now())
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.notify == nil || u.alertedFrom.Equal(month) {
+		return
+	}
+	totals, err := u.repo.Totals(month)
+	if err != nil {
+		log.Printf("Ошибка подсчета расхода за месяц: %v", err)
+		return
+	}
+	threshold := u.limits.MonthlyBudget * u.limits.AlertPercent / 100
+	if totals.Cost < threshold {
+		return
+	}
+	u.alertedFrom = month
+	u.notify(u.limits.AdminChatID, fmt.Sprintf("⚠️ Расход на генерацию за месяц: $%.2f из бюджета $%.2f (%.0f%%). Подробнее: /usage",
+		totals.Cost, u.limits.MonthlyBudget, totals.Cost/u.limits.MonthlyBudget*100))
+}
+
+// UsageReport — расход редактора и, для администратора, всего бота.
+type UsageReport struct {
+	Day, Month domain.UsageTotals
+	// Заполняются только для администратора
+	All      domain.UsageTotals
+	Channels map[int64]domain.UsageTotals
+}
+
+// Report собирает отчет о расходе редактора за сегодня и за месяц.
+func (u *UsageUsecase) Report(userID int64) (UsageReport, error) {
+	now := time.Now()
+	var r UsageReport
+	var err error
+	if r.Day, err = u.repo.UserTotals(userID, dayStart(now)); err != nil {
+		return UsageReport{}, err
+	}
+	if r.Month, err = u.repo.UserTotals(userID, monthStart(now)); err != nil {
+		return UsageReport{}, err
+	}
+	if !u.IsAdmin(userID) {
+		return r, nil
+	}
+	if r.All, err = u.repo.Totals(monthStart(now)); err != nil {
+		return UsageReport{}, err
+	}
+	if r.Channels, err = u.repo.ChannelTotals(monthStart(now)); err != nil {
+		return UsageReport{}, err
+	}
+	return r, nil
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"fmt"
+	"lady/internal/domain"
+	"lady/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeZoneAliases — названия городов, которые редакторы пишут вместо имен IANA.
+var timeZoneAliases = map[string]string{
+	"москва":          "Europe/Moscow",
+	"мск":             "Europe/Moscow",
+	"санкт-петербург": "Europe/Moscow",
+	"питер":           "Europe/Moscow",
+	"калининград":     "Europe/Kaliningrad",
+	"самара":          "Europe/Samara",
+	"екатеринбург":    "Asia/Yekaterinburg",
+	"омск":            "Asia/Omsk",
+	"новосибирск":     "Asia/Novosibirsk",
+	"красноярск":      "Asia/Krasnoyarsk",
+	"иркутск":         "Asia/Irkutsk",
+	"владивосток":     "Asia/Vladivostok",
+	"алматы":          "Asia/Almaty",
+	"астана":          "Asia/Almaty",
+	"ташкент":         "Asia/Tashkent",
+	"минск":           "Europe/Minsk",
+	"киев":            "Europe/Kyiv",
+	"тбилиси":         "Asia/Tbilisi",
+	"ереван":          "Asia/Yerevan",
+	"utc":             "UTC",
+}
+
+// UserUsecase управляет личными настройками редакторов.
+type UserUsecase struct {
+	repo *repository.UserRepository
+}
+
+// NewUserUsecase создает новый экземпляр UserUsecase.
+func NewUserUsecase(r *repository.UserRepository) *UserUsecase {
+	return &UserUsecase{repo: r}
+}
+
+// Location возвращает часовой пояс редактора.
+func (u *UserUsecase) Location(userID int64) *time.Location {
+	user, err := u.repo.Get(userID)
+	if err != nil {
+		return domain.User{}.Location()
+	}
+	return user.Location()
+}
+
+// SetTimeZone сохраняет часовой пояс редактора. Принимает имя IANA
+// (Europe/Moscow), город из timeZoneAliases или смещение вида UTC+5.
+func (u *UserUsecase) SetTimeZone(userID int64, input string) (*time.Location, error) {
+	name, err := resolveTimeZone(input)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный часовой пояс %q", input)
+	}
+	if err := u.repo.SetTimeZone(userID, name); err != nil {
+		return nil, err
+	}
+	return loc, nil
+}
+
+func resolveTimeZone(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("не указан часовой пояс")
+	}
+	if name, ok := timeZoneAliases[strings.ToLower(input)]; ok {
+		return name, nil
+	}
+
+	// Смещения UTC+5, GMT-3, +7. В базе IANA знак у Etc/GMT обратный.
+	offset := strings.ToUpper(input)
+	offset = strings.TrimPrefix(strings.TrimPrefix(offset, "UTC"), "GMT")
+	if offset != input && offset == "" {
+		return "UTC", nil
+	}
+	if strings.HasPrefix(offset, "+") || strings.HasPrefix(offset, "-") {
+		hours, err := strconv.Atoi(offset[1:])
+		if err != nil || hours > 14 {
+			return "", fmt.Errorf("неизвестный часовой пояс %q", input)
+		}
+		if hours == 0 {
+			return "UTC", nil
+		}
+		if offset[0] == '+' {
+			return fmt.Sprintf("Etc/GMT-%d", hours), nil
+		}
+		return fmt.Sprintf("Etc/GMT+%d", hours), nil
+	}
+	return input, nil
+}